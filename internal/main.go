@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"sql-translator/internal/translator"
 )
@@ -11,6 +12,10 @@ func main() {
 	translator := translator.NewSQLiteTranslator(query)
 	translator.ShowSyntaxTree()
 
-	res := translator.Translate()
+	res, err := translator.Translate()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Original: %s\nError: %v\n", query, err)
+		os.Exit(1)
+	}
 	fmt.Printf("Original: %s\nTranslated: %s\n", query, res)
 }
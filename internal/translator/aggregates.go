@@ -0,0 +1,35 @@
+package translator
+
+// aggregate describes the DuckDB counterpart of a SQLite aggregate function.
+// The call is built like any other, so DISTINCT, ORDER BY, FILTER and OVER
+// carry over.
+type aggregate struct {
+	// name is the DuckDB function called
+	name string
+	// args completes the translated arguments, if set
+	args func(args []string) []string
+	// result wraps the call, it holds a single %s
+	result string
+}
+
+// aggregates holds the aggregate functions keyed by lower-case SQLite
+// function name. DuckDB has count, sum, avg, min, max and string_agg with
+// SQLite's semantics.
+var aggregates = map[string]aggregate{
+	// group_concat separates values with a comma unless told otherwise
+	"group_concat": {
+		name: "string_agg",
+		args: func(args []string) []string {
+			if len(args) == 1 {
+				return append(args, "','")
+			}
+			return args
+		},
+		result: "%s",
+	},
+	// total is a sum that gives 0.0 over no rows or only NULLs
+	"total": {
+		name:   "sum",
+		result: "coalesce(%s, 0.0)",
+	},
+}
@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestAggregateFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "group_concat",
+			input:    "SELECT dept, group_concat(name), group_concat(name, '; ') FROM staff GROUP BY dept",
+			expected: "SELECT dept, string_agg(name, ','), string_agg(name, '; ') FROM staff GROUP BY dept",
+		},
+		{
+			name:     "group_concat distinct with a filter",
+			input:    "SELECT group_concat(DISTINCT tag) FILTER (WHERE tag <> '') FROM tags",
+			expected: "SELECT string_agg(DISTINCT tag, ',') FILTER (WHERE tag <> '') FROM tags",
+		},
+		{
+			name:     "group_concat as a window function",
+			input:    "SELECT group_concat(name, '/') OVER (ORDER BY id) FROM t",
+			expected: "SELECT string_agg(name, '/') OVER (ORDER BY id) FROM t",
+		},
+		{
+			name:     "total",
+			input:    "SELECT total(amount), total(DISTINCT amount) FROM payments",
+			expected: "SELECT coalesce(sum(amount), 0.0), coalesce(sum(DISTINCT amount), 0.0) FROM payments",
+		},
+		{
+			name:     "total with a filter and a window",
+			input:    "SELECT total(amount) FILTER (WHERE paid) OVER w FROM payments WINDOW w AS (PARTITION BY customer ORDER BY day)",
+			expected: "SELECT coalesce(sum(amount) FILTER (WHERE paid) OVER w, 0.0) FROM payments WINDOW w AS (PARTITION BY customer ORDER BY day)",
+		},
+		{
+			name:     "unchanged aggregates",
+			input:    "SELECT count(*), count(DISTINCT a), sum(a), avg(a), min(a), max(a) FROM t",
+			expected: "SELECT count(*), count(DISTINCT a), sum(a), avg(a), min(a), max(a) FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
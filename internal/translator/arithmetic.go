@@ -0,0 +1,183 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// Arithmetic decides how / and % translate when the translator cannot tell
+// whether their operands are integers. SQLite divides two integers without
+// a fraction, DuckDB's / always gives one, and SQLite's % truncates real
+// operands to integers where DuckDB's keeps the fraction.
+type Arithmetic int
+
+const (
+	// ArithmeticWarn keeps the operator and reports a warning.
+	ArithmeticWarn Arithmetic = iota
+	// ArithmeticReal assumes unknown operands are real numbers.
+	ArithmeticReal
+	// ArithmeticInteger assumes unknown operands are integers.
+	ArithmeticInteger
+)
+
+// numberKind is what is known about the type of a numeric expression.
+type numberKind int
+
+const (
+	unknownNumber numberKind = iota
+	integerNumber
+	realNumber
+)
+
+// combine gives the kind of the result of an arithmetic operator applied to
+// numbers of kinds k and other, SQLite only switches to real when an
+// operand is real.
+func (k numberKind) combine(other numberKind) numberKind {
+	switch {
+	case k == realNumber || other == realNumber:
+		return realNumber
+	case k == integerNumber && other == integerNumber:
+		return integerNumber
+	}
+	return unknownNumber
+}
+
+// integerFunctions and realFunctions are the functions whose result type
+// is known whatever their arguments.
+var (
+	integerFunctions = map[string]bool{"count": true, "length": true, "octet_length": true, "instr": true, "unicode": true, "random": true}
+	realFunctions    = map[string]bool{"avg": true, "total": true, "julianday": true}
+)
+
+// numberKind tells whether expr gives an integer or a real number, from its
+// literals, casts and the declared types of its columns.
+func (c *translatorCore) numberKind(expr parser.IExprContext) numberKind {
+	switch {
+	case expr.Literal_value() != nil:
+		literal := expr.Literal_value()
+		switch {
+		case literal.NUMERIC_LITERAL() != nil:
+			text := strings.ToLower(literal.GetText())
+			if !strings.HasPrefix(text, "0x") && strings.ContainsAny(text, ".e") {
+				return realNumber
+			}
+			return integerNumber
+		case literal.NULL_() != nil, literal.TRUE_() != nil, literal.FALSE_() != nil:
+			return integerNumber
+		}
+		return unknownNumber
+
+	case expr.Column_name() != nil:
+		var qualifier string
+		if table := expr.Table_name(); table != nil {
+			qualifier = table.GetText()
+		}
+		if _, ok := c.rowidColumn(qualifier, expr.Column_name().GetText()); ok {
+			return integerNumber
+		}
+		if affinity, ok := c.declaredAffinity(expr); ok {
+			return affinityKind(affinity)
+		}
+		return unknownNumber
+
+	case expr.CAST_() != nil:
+		return affinityKind(affinity(expr.Type_name().GetText()))
+
+	case expr.Function_name() != nil:
+		name := strings.ToLower(unquoteIdentifier(expr.Function_name().GetText()))
+		switch {
+		case integerFunctions[name]:
+			return integerNumber
+		case realFunctions[name]:
+			return realNumber
+		}
+		return unknownNumber
+
+	case expr.Unary_operator() != nil:
+		if expr.Unary_operator().MINUS() != nil || expr.Unary_operator().PLUS() != nil {
+			return c.numberKind(expr.Expr(0))
+		}
+		return integerNumber
+	}
+
+	exprs := expr.AllExpr()
+	if len(exprs) == 1 && expr.OPEN_PAR() != nil && expr.GetChildCount() == 3 {
+		return c.numberKind(exprs[0])
+	}
+	if len(exprs) == 2 && expr.GetChildCount() == 3 {
+		if operator, ok := expr.GetChild(1).(antlr.TerminalNode); ok {
+			switch operator.GetText() {
+			case "+", "-", "*", "/", "%":
+				return c.numberKind(exprs[0]).combine(c.numberKind(exprs[1]))
+			}
+		}
+	}
+	return unknownNumber
+}
+
+// declaredAffinity returns the affinity of a column from its declared type,
+// or of a CAST from the type it casts to. ok is false for other
+// expressions and for columns of tables not in the schema.
+func (c *translatorCore) declaredAffinity(expr parser.IExprContext) (string, bool) {
+	switch {
+	case expr.CAST_() != nil:
+		return affinity(expr.Type_name().GetText()), true
+	case expr.Column_name() == nil || expr.Literal_value() != nil:
+		return "", false
+	}
+
+	var qualifier string
+	if table := expr.Table_name(); table != nil {
+		qualifier = table.GetText()
+	}
+	if table, ok := c.resolveTable(qualifier); ok {
+		if schema := c.schema[table.name]; schema != nil {
+			if declared, ok := schema.columns[strings.ToLower(unquoteIdentifier(expr.Column_name().GetText()))]; ok {
+				return affinity(declared), true
+			}
+		}
+	}
+	return "", false
+}
+
+func affinityKind(affinity string) numberKind {
+	switch affinity {
+	case integerAffinity:
+		return integerNumber
+	case realAffinity:
+		return realNumber
+	}
+	return unknownNumber
+}
+
+// division translates / and %. Integers divide with //, real operands of %
+// are truncated first and give a real remainder, as in SQLite.
+func (c *translatorCore) division(ctx *parser.ExprContext, operator string) (string, int) {
+	kind := c.numberKind(ctx.Expr(0)).combine(c.numberKind(ctx.Expr(1)))
+	if kind == unknownNumber {
+		switch c.arithmetic {
+		case ArithmeticReal:
+			kind = realNumber
+		case ArithmeticInteger:
+			kind = integerNumber
+		default:
+			if operator == "/" {
+				c.warnf(ctx, "division kept as is, the operand types are unknown and SQLite divides integers without a fraction")
+			} else {
+				c.warnf(ctx, "remainder kept as is, the operand types are unknown and SQLite truncates real operands to integers")
+			}
+		}
+	}
+
+	if operator == "%" && kind == realNumber {
+		return fmt.Sprintf("CAST(CAST(trunc(%s) AS BIGINT) %% CAST(trunc(%s) AS BIGINT) AS DOUBLE)", c.visitString(ctx.Expr(0)), c.visitString(ctx.Expr(1))), duckDBPrimary
+	}
+	if operator == "/" && kind == integerNumber {
+		operator = "//"
+	}
+	return fmt.Sprintf("%s %s %s", c.operand(ctx.Expr(0), duckDBMultiplicative), operator, c.operand(ctx.Expr(1), duckDBMultiplicative+1)), duckDBMultiplicative
+}
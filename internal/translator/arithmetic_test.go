@@ -0,0 +1,82 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestArithmetic(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		arithmetic Arithmetic
+		expected   string
+		warnings   int
+	}{
+		{
+			name:     "integer literals",
+			input:    "SELECT 5 / 2, -7 / (1 + 1), 5 % 3",
+			expected: "SELECT 5 // 2, -7 // (1 + 1), 5 % 3",
+		},
+		{
+			name:     "real literals",
+			input:    "SELECT 5.0 / 2, 5 / 2e0, 5.5 % 2",
+			expected: "SELECT 5.0 / 2, 5 / 2e0, CAST(CAST(trunc(5.5) AS BIGINT) % CAST(trunc(2) AS BIGINT) AS DOUBLE)",
+		},
+		{
+			name:     "casts",
+			input:    "SELECT CAST(a AS INTEGER) / 3, CAST(a AS REAL) / 3 FROM t",
+			expected: "SELECT CAST(a AS BIGINT) // 3, CAST(a AS DOUBLE) / 3 FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "declared column types",
+			input:    "CREATE TABLE t (id INTEGER PRIMARY KEY, qty INT, price REAL); SELECT qty / 2, price / qty, rowid / 10, t.qty % price, count(*) / qty FROM t",
+			expected: "CREATE SEQUENCE t_id_seq;\nCREATE TABLE t (id BIGINT PRIMARY KEY DEFAULT nextval('t_id_seq'), qty BIGINT, price DOUBLE);\nSELECT qty // 2, price / qty, id // 10, CAST(CAST(trunc(t.qty) AS BIGINT) % CAST(trunc(price) AS BIGINT) AS DOUBLE), count(*) // qty FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "real remainder divided",
+			input:    "CREATE TABLE t (qty INT, price REAL); SELECT (5.5 % 2) / 2, price % 2 / qty, (qty % 2) / 2 FROM t",
+			expected: "CREATE TABLE t (qty BIGINT, price DOUBLE);\nSELECT (CAST(CAST(trunc(5.5) AS BIGINT) % CAST(trunc(2) AS BIGINT) AS DOUBLE)) / 2, CAST(CAST(trunc(price) AS BIGINT) % CAST(trunc(2) AS BIGINT) AS DOUBLE) / qty, (qty % 2) // 2 FROM t",
+		},
+		{
+			name:     "unknown operands",
+			input:    "SELECT a / b, a % 2 FROM t",
+			expected: "SELECT a / b, a % 2 FROM t",
+			warnings: 2,
+		},
+		{
+			name:       "unknown operands assumed real",
+			input:      "SELECT a / b, a % 2 FROM t",
+			arithmetic: ArithmeticReal,
+			expected:   "SELECT a / b, CAST(CAST(trunc(a) AS BIGINT) % CAST(trunc(2) AS BIGINT) AS DOUBLE) FROM t",
+		},
+		{
+			name:       "unknown operands assumed integer",
+			input:      "SELECT a / b, a % 2 FROM t",
+			arithmetic: ArithmeticInteger,
+			expected:   "SELECT a // b, a % 2 FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+				arithmetic:              tt.arithmetic,
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
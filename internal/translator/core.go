@@ -2,7 +2,9 @@ package translator
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"sql-translator/internal/parser"
 
@@ -11,6 +13,31 @@ import (
 
 type translatorCore struct {
 	*parser.BaseSQLiteParserVisitor
+
+	mode  Mode
+	types *TypeMap
+	// nullOrder makes every ordering term state where NULLs go
+	nullOrder bool
+	// arithmetic is how / and % translate when operand types are unknown
+	arithmetic Arithmetic
+	// caseSensitiveLike keeps LIKE instead of using ILIKE
+	caseSensitiveLike bool
+	// concatFunction translates || to concat()
+	concatFunction bool
+
+	// schema holds the tables created so far, keyed by lower-case name, and
+	// scopes the tables visible to the statement being translated.
+	schema map[string]*tableSchema
+	scopes [][]scopeTable
+
+	// compound holds the select cores of the compound SELECT whose ORDER BY
+	// is being translated, nil anywhere else.
+	compound []parser.ISelect_coreContext
+
+	// tokens is the stream the tree was parsed from. It is optional and only
+	// used to recover comments and source text hidden from the tree.
+	tokens      *antlr.CommonTokenStream
+	diagnostics Diagnostics
 }
 
 func (c *translatorCore) Visit(tree antlr.ParseTree) any {
@@ -20,30 +47,132 @@ func (c *translatorCore) Visit(tree antlr.ParseTree) any {
 	return tree.Accept(c)
 }
 
+// visitString visits tree and returns its translation. Rules that have no
+// translation yield an empty string rather than a nil interface.
+func (c *translatorCore) visitString(tree antlr.ParseTree) string {
+	s, _ := c.Visit(tree).(string)
+	return s
+}
+
 func (c *translatorCore) VisitParse(ctx *parser.ParseContext) any {
-	return c.Visit(ctx.Sql_stmt_list(0))
+	var stmts []string
+	for _, list := range ctx.AllSql_stmt_list() {
+		if stmt := c.visitString(list); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	script := strings.Join(stmts, ";\n")
+
+	// Comments after the last statement are attached to EOF
+	if eof := ctx.EOF(); eof != nil {
+		if comments := c.commentsBefore(eof.GetSymbol()); comments != "" {
+			if script == "" {
+				return comments
+			}
+			script = fmt.Sprintf("%s\n%s", script, comments)
+		}
+	}
+
+	return script
 }
 
 func (c *translatorCore) VisitSql_stmt_list(ctx *parser.Sql_stmt_listContext) any {
-	return c.Visit(ctx.Sql_stmt(0))
+	var stmts []string
+	for _, stmtCtx := range ctx.AllSql_stmt() {
+		stmt := c.visitString(stmtCtx)
+		if stmt == "" {
+			continue
+		}
+
+		if comments := c.commentsBefore(stmtCtx.GetStart()); comments != "" {
+			stmt = fmt.Sprintf("%s\n%s", comments, stmt)
+		}
+		stmts = append(stmts, stmt)
+	}
+
+	return strings.Join(stmts, ";\n")
 }
 
 func (c *translatorCore) VisitSql_stmt(ctx *parser.Sql_stmtContext) any {
-	return c.Visit(ctx.Select_stmt())
+	// The statement itself is always the last child, after any EXPLAIN prefix
+	stmt, ok := ctx.GetChild(ctx.GetChildCount() - 1).(antlr.ParserRuleContext)
+	if !ok {
+		return ""
+	}
+
+	var query string
+	switch stmt.(type) {
+	case *parser.Select_stmtContext, *parser.Create_table_stmtContext, *parser.Insert_stmtContext,
+		*parser.Update_stmtContext, *parser.Update_stmt_limitedContext,
+		*parser.Delete_stmtContext, *parser.Delete_stmt_limitedContext, *parser.Pragma_stmtContext:
+		query = c.visitString(stmt)
+	default:
+		query = c.unsupported(stmt)
+	}
+
+	// DuckDB has no separate QUERY PLAN form, plain EXPLAIN is the equivalent
+	if ctx.EXPLAIN_() != nil {
+		query = fmt.Sprintf("EXPLAIN %s", query)
+	}
+
+	return query
+}
+
+// commentsBefore returns the comments found between the previous default
+// channel token and tok, one per line. Without a token stream there is
+// nothing to recover and the result is empty.
+func (c *translatorCore) commentsBefore(tok antlr.Token) string {
+	if c.tokens == nil || tok == nil || tok.GetTokenIndex() < 0 {
+		return ""
+	}
+
+	var comments []string
+	for _, hidden := range c.tokens.GetHiddenTokensToLeft(tok.GetTokenIndex(), -1) {
+		switch hidden.GetTokenType() {
+		case parser.SQLiteLexerSINGLE_LINE_COMMENT, parser.SQLiteLexerMULTILINE_COMMENT:
+			comments = append(comments, strings.TrimSpace(hidden.GetText()))
+		}
+	}
+
+	return strings.Join(comments, "\n")
 }
 
 func (c *translatorCore) VisitSelect_stmt(ctx *parser.Select_stmtContext) any {
-	selectCore := ctx.Select_core(0)
-	if selectCore == nil {
+	cores := ctx.AllSelect_core()
+	if len(cores) == 0 {
 		return ""
 	}
 
-	query := c.Visit(selectCore).(string)
+	query := c.visitString(cores[0])
+	for i, op := range ctx.AllCompound_operator() {
+		query = fmt.Sprintf("%s %s %s", query, compoundOperator(op), c.visitString(cores[i+1]))
+	}
+	if with := ctx.Common_table_stmt(); with != nil {
+		query = fmt.Sprintf("%s %s", c.Visit(with), query)
+	}
+
+	// ORDER BY of a simple SELECT may refer to the tables of the core, rowid
+	// in particular. The one of a compound only sees the result columns.
+	outer := c.compound
+	defer func() { c.compound = outer }()
+	c.compound = nil
+	if len(cores) > 1 {
+		c.compound = cores
+		c.pushScope(nil)
+	} else if core, ok := cores[0].(*parser.Select_coreContext); ok {
+		c.pushScope(fromScope(core))
+	} else {
+		c.pushScope(nil)
+	}
+	defer c.popScope()
 
 	if orderBy := ctx.Order_by_stmt(); orderBy != nil {
 		query = fmt.Sprintf("%s %s", query, c.Visit(orderBy))
 	}
 
+	// LIMIT expressions are evaluated once, they never see result columns
+	c.compound = nil
 	if limit := ctx.Limit_stmt(); limit != nil {
 		query = fmt.Sprintf("%s %s", query, c.Visit(limit))
 	}
@@ -51,22 +180,161 @@ func (c *translatorCore) VisitSelect_stmt(ctx *parser.Select_stmtContext) any {
 	return query
 }
 
-func (c *translatorCore) VisitSelect_core(ctx *parser.Select_coreContext) any {
-	var fromClause string
+func compoundOperator(ctx parser.ICompound_operatorContext) string {
+	switch {
+	case ctx.UNION_() != nil && ctx.ALL_() != nil:
+		return "UNION ALL"
+	case ctx.UNION_() != nil:
+		return "UNION"
+	case ctx.INTERSECT_() != nil:
+		return "INTERSECT"
+	}
+	return "EXCEPT"
+}
 
-	// Handle joins if they exist
-	if join := ctx.Join_clause(); join != nil {
-		fromClause = c.Visit(join).(string)
-	} else {
-		// Get the base table/subquery only if no joins
-		fromClause = c.Visit(ctx.Table_or_subquery(0)).(string)
+// compoundTerm translates an ORDER BY expression of a compound SELECT.
+// SQLite matches it against the result columns of each core in turn: by
+// number, by alias, or by being the same expression as one of them. DuckDB
+// only resolves numbers and the output names of the first core, so a term
+// matching anything else is replaced by the column number. ok is false when
+// the term should be translated as is, a term that matches nothing is an
+// error as in SQLite.
+func (c *translatorCore) compoundTerm(expr parser.IExprContext) (string, bool) {
+	if c.compound == nil {
+		return "", false
 	}
+	if literal := expr.Literal_value(); literal != nil && literal.NUMERIC_LITERAL() != nil {
+		return "", false
+	}
+
+	var name, qualifier string
+	if expr.Column_name() != nil {
+		name = strings.ToLower(unquoteIdentifier(expr.Column_name().GetText()))
+		if table := expr.Table_name(); table != nil {
+			qualifier = strings.ToLower(unquoteIdentifier(table.GetText()))
+		}
+	}
+	text := strings.ToLower(expr.GetText())
+	for k, core := range c.compound {
+		if core.Values_clause() != nil {
+			// VALUES names its columns column1, column2 and so on
+			if number, ok := strings.CutPrefix(name, "column"); ok && qualifier == "" {
+				if n, err := strconv.Atoi(number); err == nil && n > 0 && n <= len(core.Values_clause().Value_row(0).AllExpr()) {
+					return strconv.Itoa(n), true
+				}
+			}
+			continue
+		}
+
+		columns := core.AllResult_column()
+		for i, column := range columns {
+			if alias := column.Column_alias(); alias != nil && name != "" && qualifier == "" && strings.ToLower(unquoteIdentifier(alias.GetText())) == name {
+				if k == 0 {
+					return "", false
+				}
+				return strconv.Itoa(i + 1), true
+			}
+		}
+		for i, column := range columns {
+			result := column.Expr()
+			if result == nil {
+				continue
+			}
+			sameName := name != "" && result.Column_name() != nil && strings.ToLower(unquoteIdentifier(result.Column_name().GetText())) == name
+			if table := result.Table_name(); sameName && qualifier != "" && table != nil {
+				sameName = strings.ToLower(unquoteIdentifier(table.GetText())) == qualifier
+			}
+			if !sameName && strings.ToLower(result.GetText()) != text {
+				continue
+			}
+			if k == 0 && sameName && qualifier == "" && column.Column_alias() == nil && c.keepsName(core.(*parser.Select_coreContext), result) {
+				return "", false
+			}
+			return strconv.Itoa(i + 1), true
+		}
+	}
+
+	c.errorf(expr, "ORDER BY term %s does not match any column in the result set", c.sourceText(expr))
+	return "", false
+}
+
+// keepsName tells whether the column result of core keeps its name in the
+// translation, which rowid columns of tables with a rowid alias do not.
+func (c *translatorCore) keepsName(core *parser.Select_coreContext, result parser.IExprContext) bool {
+	var qualifier string
+	if table := result.Table_name(); table != nil {
+		qualifier = table.GetText()
+	}
+	name := result.Column_name().GetText()
+
+	c.pushScope(fromScope(core))
+	defer c.popScope()
+	rowid, ok := c.rowidColumn(qualifier, name)
+	return !ok || strings.EqualFold(rowid, unquoteIdentifier(name))
+}
+
+func (c *translatorCore) VisitCommon_table_stmt(ctx *parser.Common_table_stmtContext) any {
+	var tables []string
+	for _, table := range ctx.AllCommon_table_expression() {
+		tables = append(tables, c.visitString(table))
+	}
+	return fmt.Sprintf("%s %s", withKeyword(ctx.RECURSIVE_() != nil), strings.Join(tables, ", "))
+}
+
+func (c *translatorCore) VisitCommon_table_expression(ctx *parser.Common_table_expressionContext) any {
+	return c.commonTable(ctx.Table_name(), ctx.AllColumn_name(), ctx.AS_(), ctx.Select_stmt())
+}
+
+// VisitWith_clause translates the WITH clause of INSERT, UPDATE and DELETE,
+// which the grammar spells differently from the one of SELECT.
+func (c *translatorCore) VisitWith_clause(ctx *parser.With_clauseContext) any {
+	var tables []string
+	for i, name := range ctx.AllCte_table_name() {
+		tables = append(tables, c.commonTable(name.Table_name(), name.AllColumn_name(), ctx.AS_(i), ctx.Select_stmt(i)))
+	}
+	return fmt.Sprintf("%s %s", withKeyword(ctx.RECURSIVE_() != nil), strings.Join(tables, ", "))
+}
+
+func withKeyword(recursive bool) string {
+	if recursive {
+		return "WITH RECURSIVE"
+	}
+	return "WITH"
+}
+
+// commonTable renders `name (columns) AS [hint] (query)`. DuckDB accepts
+// the MATERIALIZED and NOT MATERIALIZED hints as SQLite spells them.
+func (c *translatorCore) commonTable(table parser.ITable_nameContext, columns []parser.IColumn_nameContext, as antlr.TerminalNode, query parser.ISelect_stmtContext) string {
+	name := c.name(table)
+	if len(columns) > 0 {
+		var names []string
+		for _, column := range columns {
+			names = append(names, c.name(column))
+		}
+		name = fmt.Sprintf("%s (%s)", name, strings.Join(names, ", "))
+	}
+	return fmt.Sprintf("%s AS %s(%s)", name, c.materializedHint(as), c.Visit(query))
+}
+
+func (c *translatorCore) VisitSelect_core(ctx *parser.Select_coreContext) any {
+	if values := ctx.Values_clause(); values != nil {
+		return c.Visit(values)
+	}
+
+	c.pushScope(fromScope(ctx))
+	defer c.popScope()
 
 	columnStr := c.buildColumns(ctx)
+	fromClause := c.buildFromClause(ctx)
 	whereClause := c.buildWhereClause(ctx)
 	groupByClause := c.buildGroupByClause(ctx)
+	windowClause := c.buildWindowClause(ctx)
 
-	query := fmt.Sprintf("SELECT %s FROM %s", columnStr, fromClause)
+	query := fmt.Sprintf("%s %s", selectKeyword(ctx), columnStr)
+
+	if fromClause != "" {
+		query = fmt.Sprintf("%s %s", query, fromClause)
+	}
 
 	if whereClause != "" {
 		query = fmt.Sprintf("%s %s", query, whereClause)
@@ -76,42 +344,69 @@ func (c *translatorCore) VisitSelect_core(ctx *parser.Select_coreContext) any {
 		query = fmt.Sprintf("%s %s", query, groupByClause)
 	}
 
+	if windowClause != "" {
+		query = fmt.Sprintf("%s %s", query, windowClause)
+	}
+
 	return query
 }
 
 func (c *translatorCore) VisitTable_or_subquery(ctx *parser.Table_or_subqueryContext) any {
-	if ctx.Table_name() != nil {
-		tableName := ctx.Table_name().GetText()
-		// Remove any join type keywords that might be concatenated
-		tableName = strings.TrimSuffix(tableName, "INNER")
-		tableName = strings.TrimSuffix(tableName, "LEFT")
-		tableName = strings.TrimSuffix(tableName, "CROSS")
-		tableName = strings.TrimSuffix(tableName, "NATURAL")
-		return tableName
-	}
+	switch {
+	case ctx.Table_name() != nil:
+		table := c.qualifiedName(ctx.Schema_name(), ctx.Table_name())
+		if ctx.INDEXED_() != nil {
+			c.warnf(ctx, "index hint dropped, DuckDB chooses indexes itself")
+		}
+		// A join keyword swallowed as alias belongs to the join operator
+		if alias := ctx.Table_alias(); alias != nil && joinKeywordAlias(ctx) == "" {
+			return fmt.Sprintf("%s AS %s", table, c.name(alias))
+		}
+		return table
 
-	if ctx.Select_stmt() != nil {
+	case ctx.Select_stmt() != nil:
 		subquery := c.Visit(ctx.Select_stmt())
 		if ctx.Table_alias() != nil {
-			return fmt.Sprintf("(%s) AS %s", subquery, ctx.Table_alias().GetText())
+			return fmt.Sprintf("(%s) AS %s", subquery, c.name(ctx.Table_alias()))
 		}
 		return fmt.Sprintf("(%s)", subquery)
+
+	case ctx.Join_clause() != nil:
+		return fmt.Sprintf("(%s)", c.Visit(ctx.Join_clause()))
+
+	case ctx.Table_function_name() != nil && tableFunctions[strings.ToLower(unquoteIdentifier(ctx.Table_function_name().GetText()))]:
+		var args []string
+		for _, expr := range ctx.AllExpr() {
+			args = append(args, c.visitString(expr))
+		}
+		call := fmt.Sprintf("%s(%s)", c.qualifiedName(ctx.Schema_name(), ctx.Table_function_name()), strings.Join(args, ", "))
+		if alias := ctx.Table_alias(); alias != nil && joinKeywordAlias(ctx) == "" {
+			return fmt.Sprintf("%s AS %s", call, c.name(alias))
+		}
+		return call
+
+	case ctx.Table_function_name() == nil && len(ctx.AllTable_or_subquery()) > 0:
+		var tables []string
+		for _, table := range ctx.AllTable_or_subquery() {
+			tables = append(tables, c.visitString(table))
+		}
+		return fmt.Sprintf("(%s)", strings.Join(tables, ", "))
 	}
 
-	return strings.TrimSuffix(ctx.GetText(), "INNER")
+	return c.unsupported(ctx)
 }
 
 func (c *translatorCore) VisitResult_column(ctx *parser.Result_columnContext) any {
 	if ctx.STAR() != nil {
 		if table := ctx.Table_name(); table != nil {
-			return fmt.Sprintf("%s.*", table.GetText())
+			return fmt.Sprintf("%s.*", c.name(table))
 		}
 		return "*"
 	}
 
 	if ctx.Column_alias() != nil {
 		expr := c.Visit(ctx.Expr())
-		alias := ctx.Column_alias().GetText()
+		alias := c.Visit(ctx.Column_alias())
 		return fmt.Sprintf("%s AS %s", expr, alias)
 	}
 
@@ -119,6 +414,13 @@ func (c *translatorCore) VisitResult_column(ctx *parser.Result_columnContext) an
 		return c.Visit(ctx.Expr())
 	}
 
+	return c.unsupported(ctx)
+}
+
+func (c *translatorCore) VisitColumn_alias(ctx *parser.Column_aliasContext) any {
+	if id := ctx.IDENTIFIER(); id != nil {
+		return normalizeIdentifier(id.GetText())
+	}
 	return ctx.GetText()
 }
 
@@ -126,55 +428,215 @@ func (c *translatorCore) VisitExpr(ctx *parser.ExprContext) interface{} {
 	if ctx == nil {
 		return nil
 	}
+	translated, _ := c.expression(ctx)
+	return translated
+}
 
-	if ctx.EXISTS_() != nil {
-		return fmt.Sprintf("EXISTS (%s)", c.Visit(ctx.Select_stmt()))
-	}
+// expression translates ctx and returns the DuckDB precedence of the
+// outermost operator of the translation, see operand.
+func (c *translatorCore) expression(ctx *parser.ExprContext) (string, int) {
+	switch {
+	case ctx.Literal_value() != nil:
+		return c.visitString(ctx.Literal_value()), duckDBPrimary
+
+	case ctx.BIND_PARAMETER() != nil:
+		return ctx.BIND_PARAMETER().GetText(), duckDBPrimary
+
+	case ctx.Column_name() != nil:
+		column := c.name(ctx.Column_name())
+		table := ctx.Table_name()
+
+		var qualifier string
+		if table != nil {
+			qualifier = table.GetText()
+		}
+		if rowid, ok := c.rowidColumn(qualifier, ctx.Column_name().GetText()); ok {
+			column = rowid
+		}
+
+		if table != nil {
+			return fmt.Sprintf("%s.%s", c.qualifiedName(ctx.Schema_name(), table), column), duckDBPrimary
+		}
+		return column, duckDBPrimary
 
-	if ctx.Select_stmt() != nil {
-		return fmt.Sprintf("(%s)", c.Visit(ctx.Select_stmt()))
+	case ctx.Function_name() != nil:
+		return c.buildFunctionCall(ctx), duckDBPrimary
+
+	case ctx.Unary_operator() != nil:
+		if ctx.Unary_operator().NOT_() != nil {
+			return fmt.Sprintf("NOT %s", c.operand(ctx.Expr(0), duckDBNot)), duckDBNot
+		}
+		operand := c.operand(ctx.Expr(0), duckDBUnary)
+		// Two minus signs in a row would start a comment
+		if ctx.Unary_operator().MINUS() != nil && strings.HasPrefix(operand, "-") {
+			return fmt.Sprintf("- %s", operand), duckDBUnary
+		}
+		return fmt.Sprintf("%s%s", ctx.Unary_operator().GetText(), operand), duckDBUnary
+
+	case ctx.EXISTS_() != nil:
+		if ctx.NOT_() != nil {
+			return fmt.Sprintf("NOT EXISTS (%s)", c.Visit(ctx.Select_stmt())), duckDBNot
+		}
+		return fmt.Sprintf("EXISTS (%s)", c.Visit(ctx.Select_stmt())), duckDBPrimary
+
+	case ctx.Select_stmt() != nil && ctx.GetChildCount() == 3:
+		return fmt.Sprintf("(%s)", c.Visit(ctx.Select_stmt())), duckDBPrimary
+
+	case ctx.CAST_() != nil:
+		return c.castExpr(ctx), duckDBPrimary
+
+	case ctx.CASE_() != nil:
+		return c.caseExpr(ctx), duckDBPrimary
+
+	case ctx.Raise_function() != nil:
+		return c.raise(ctx), duckDBPrimary
+
+	case ctx.BETWEEN_() != nil:
+		return c.betweenExpr(ctx), duckDBIn
+
+	case ctx.ISNULL_() != nil || ctx.NOTNULL_() != nil || ctx.NULL_() != nil:
+		return c.nullTest(ctx), duckDBIs
+
+	case ctx.IS_() != nil:
+		return c.isExpr(ctx), duckDBIs
+
+	case ctx.IN_() != nil:
+		return c.inExpr(ctx)
+
+	case ctx.LIKE_() != nil || ctx.GLOB_() != nil:
+		return c.patternMatch(ctx), duckDBIn
+
+	case ctx.REGEXP_() != nil:
+		return c.regexpExpr(ctx)
+
+	case ctx.MATCH_() != nil:
+		c.unsupportedf(ctx, "MATCH has no DuckDB equivalent, it queries full-text search tables")
+		return c.sourceText(ctx), duckDBIn
+
+	case ctx.COLLATE_() != nil:
+		collation, ok := c.collation(ctx.Collation_name())
+		if !ok {
+			return c.expression(ctx.Expr(0).(*parser.ExprContext))
+		}
+		return fmt.Sprintf("%s COLLATE %s", c.operand(ctx.Expr(0), duckDBCollate), collation), duckDBCollate
 	}
 
 	exprs := ctx.AllExpr()
-	if len(exprs) == 2 {
-		leftExpr := c.Visit(exprs[0]).(string)
-		rightExpr := c.Visit(exprs[1]).(string)
-		operator := ctx.GetChild(1).(antlr.TerminalNode).GetSymbol().GetText()
-
-		if operator == "||" {
-			if parent, ok := ctx.GetParent().(*parser.ExprContext); ok && parent.GetChild(1) != nil {
-				if parentOp, ok := parent.GetChild(1).(antlr.TerminalNode); ok && parentOp.GetSymbol().GetText() == "||" {
-					return fmt.Sprintf("%s, %s", leftExpr, rightExpr)
-				}
+	if len(exprs) == 2 && ctx.GetChild(0) == exprs[0] {
+		operator, ok := c.binaryOperator(ctx)
+		if !ok {
+			return c.unsupported(ctx), duckDBPrimary
+		}
+
+		switch {
+		case operator == "->" || operator == "->>":
+			return c.jsonOperator(ctx, operator), duckDBPrimary
+		case operator == "/" || operator == "%":
+			return c.division(ctx, operator)
+		case operator == "||" && c.concatFunction:
+			return fmt.Sprintf("concat(%s)", strings.Join(c.visitAll(concatOperands(ctx)), ", ")), duckDBPrimary
+		}
+
+		precedence, leftAssociative := binaryPrecedence(operator)
+		leftPrecedence := precedence
+		if !leftAssociative {
+			leftPrecedence++
+		}
+		return fmt.Sprintf("%s %s %s", c.operand(exprs[0], leftPrecedence), operator, c.operand(exprs[1], precedence+1)), precedence
+	}
+
+	// Parenthesised expression or row value
+	if ctx.OPEN_PAR() != nil && ctx.GetChild(0) == ctx.OPEN_PAR() && len(exprs) > 0 {
+		return fmt.Sprintf("(%s)", strings.Join(c.visitAll(exprs), ", ")), duckDBPrimary
+	}
+
+	return c.unsupported(ctx), duckDBPrimary
+}
+
+// concatOperands lists the operands of a chain of || operators, which
+// concat() takes all at once. Parenthesised chains are operands themselves.
+func concatOperands(ctx *parser.ExprContext) []parser.IExprContext {
+	var operands []parser.IExprContext
+	for _, operand := range ctx.AllExpr() {
+		if operand.GetChildCount() == 3 {
+			if operator, ok := operand.GetChild(1).(antlr.TerminalNode); ok && operator.GetText() == "||" {
+				operands = append(operands, concatOperands(operand.(*parser.ExprContext))...)
+				continue
 			}
-			return fmt.Sprintf("concat(%s, %s)", leftExpr, rightExpr)
 		}
+		operands = append(operands, operand)
+	}
+	return operands
+}
 
-		return fmt.Sprintf("%s %s %s", leftExpr, operator, rightExpr)
+// binaryOperator returns the operator between the two operands of a binary
+// expression. Multi-keyword operators such as IS NOT DISTINCT FROM are
+// joined by single spaces.
+func (c *translatorCore) binaryOperator(ctx *parser.ExprContext) (string, bool) {
+	var words []string
+	for i := 1; i < ctx.GetChildCount()-1; i++ {
+		node, ok := ctx.GetChild(i).(antlr.TerminalNode)
+		if !ok {
+			return "", false
+		}
+		words = append(words, node.GetText())
 	}
+	return strings.Join(words, " "), len(words) > 0
+}
 
+func (c *translatorCore) VisitLiteral_value(ctx *parser.Literal_valueContext) any {
 	return ctx.GetText()
 }
 
 func (c *translatorCore) VisitOrder_by_stmt(ctx *parser.Order_by_stmtContext) interface{} {
 	var orderClauses []string
 
-	for i := 0; i < len(ctx.AllOrdering_term()); i++ {
-		term := ctx.Ordering_term(i)
-		expr := term.Expr().GetText()
-
-		// if direction exists (ASC/DESC)
-		if term.GetChildCount() > 1 {
-			direction := term.GetChild(1).(antlr.ParseTree).GetText()
-			orderClauses = append(orderClauses, fmt.Sprintf("%s %s", expr, direction))
-		} else {
-			orderClauses = append(orderClauses, expr)
-		}
+	for _, term := range ctx.AllOrdering_term() {
+		orderClauses = append(orderClauses, c.visitString(term))
 	}
 
 	return fmt.Sprintf("ORDER BY %s", strings.Join(orderClauses, ", "))
 }
 
+func (c *translatorCore) VisitOrdering_term(ctx *parser.Ordering_termContext) any {
+	collation, collate := "", false
+	if ctx.COLLATE_() != nil {
+		collation, collate = c.collation(ctx.Collation_name())
+	}
+
+	term, ok := c.compoundTerm(ctx.Expr())
+	switch {
+	case !ok && collate:
+		term = c.operand(ctx.Expr(), duckDBCollate)
+	case !ok:
+		term = c.visitString(ctx.Expr())
+	}
+	if collate {
+		term = fmt.Sprintf("%s COLLATE %s", term, collation)
+	}
+
+	// if direction exists (ASC/DESC)
+	descending := false
+	if direction := ctx.Asc_desc(); direction != nil {
+		descending = direction.DESC_() != nil
+		term = fmt.Sprintf("%s %s", term, strings.ToUpper(direction.GetText()))
+	}
+
+	// SQLite sorts NULLs as the smallest values, DuckDB puts them last in
+	// either direction unless told otherwise
+	switch {
+	case ctx.FIRST_() != nil:
+		term = fmt.Sprintf("%s NULLS FIRST", term)
+	case ctx.LAST_() != nil:
+		term = fmt.Sprintf("%s NULLS LAST", term)
+	case c.nullOrder && descending:
+		term = fmt.Sprintf("%s NULLS LAST", term)
+	case c.nullOrder:
+		term = fmt.Sprintf("%s NULLS FIRST", term)
+	}
+	return term
+}
+
 func (c *translatorCore) VisitLimit_stmt(ctx *parser.Limit_stmtContext) any {
 	if ctx == nil {
 		return ""
@@ -194,13 +656,35 @@ func (c *translatorCore) VisitLimit_stmt(ctx *parser.Limit_stmtContext) any {
 func (c *translatorCore) buildColumns(ctx *parser.Select_coreContext) string {
 	var columns []string
 	for i := 0; i < len(ctx.AllResult_column()); i++ {
-		col := c.Visit(ctx.Result_column(i)).(string)
+		col := c.visitString(ctx.Result_column(i))
 		columns = append(columns, col)
 	}
 
 	return strings.Join(columns, ", ")
 }
 
+func (c *translatorCore) buildFromClause(ctx *parser.Select_coreContext) string {
+	if ctx.FROM_() == nil {
+		return ""
+	}
+	return fmt.Sprintf("FROM %s", c.tableList(ctx.AllTable_or_subquery(), ctx.Join_clause()))
+}
+
+// tableList renders the tables of a FROM clause, either a join clause or a
+// comma separated list.
+func (c *translatorCore) tableList(tables []parser.ITable_or_subqueryContext, join parser.IJoin_clauseContext) string {
+	// Handle joins if they exist
+	if join != nil {
+		return c.visitString(join)
+	}
+
+	var result []string
+	for _, table := range tables {
+		result = append(result, c.visitString(table))
+	}
+	return strings.Join(result, ", ")
+}
+
 func (c *translatorCore) buildWhereClause(ctx *parser.Select_coreContext) string {
 	if whereExpr := ctx.GetWhereExpr(); whereExpr != nil {
 		return fmt.Sprintf("WHERE %s", c.Visit(whereExpr))
@@ -208,15 +692,32 @@ func (c *translatorCore) buildWhereClause(ctx *parser.Select_coreContext) string
 	return ""
 }
 
+func selectKeyword(ctx *parser.Select_coreContext) string {
+	switch {
+	case ctx.DISTINCT_() != nil:
+		return "SELECT DISTINCT"
+	case ctx.ALL_() != nil:
+		return "SELECT ALL"
+	}
+	return "SELECT"
+}
+
 func (c *translatorCore) buildGroupByClause(ctx *parser.Select_coreContext) string {
-	if groupByExprs := ctx.GetGroupByExpr(); len(groupByExprs) > 0 {
-		var columns []string
-		for _, expr := range groupByExprs {
-			columns = append(columns, expr.GetText())
-		}
-		return fmt.Sprintf("GROUP BY %s", strings.Join(columns, ", "))
+	groupByExprs := ctx.GetGroupByExpr()
+	if len(groupByExprs) == 0 {
+		return ""
 	}
-	return ""
+
+	var columns []string
+	for _, expr := range groupByExprs {
+		columns = append(columns, c.visitString(expr))
+	}
+	clause := fmt.Sprintf("GROUP BY %s", strings.Join(columns, ", "))
+
+	if having := ctx.GetHavingExpr(); having != nil {
+		clause = fmt.Sprintf("%s HAVING %s", clause, c.Visit(having))
+	}
+	return clause
 }
 
 func (c *translatorCore) VisitJoin_clause(ctx *parser.Join_clauseContext) any {
@@ -224,39 +725,77 @@ func (c *translatorCore) VisitJoin_clause(ctx *parser.Join_clauseContext) any {
 		return ""
 	}
 
-	result := c.Visit(ctx.Table_or_subquery(0)).(string)
-
-	for i := 0; i < len(ctx.AllJoin_operator()); i++ {
-		joinOp := ctx.Join_operator(i)
-		table := c.Visit(ctx.Table_or_subquery(i + 1)).(string)
+	// Children come in source order: table (operator table constraint?)*.
+	// Walking them keeps every constraint next to the join it belongs to.
+	var result, joinPrefix string
+	for _, child := range ctx.GetChildren() {
+		switch child := child.(type) {
+		case *parser.Table_or_subqueryContext:
+			table := c.visitString(child)
+			if result == "" {
+				result = table
+			} else {
+				result = fmt.Sprintf("%s %s", result, table)
+			}
+			joinPrefix = joinKeywordAlias(child)
+		case *parser.Join_operatorContext:
+			if child.COMMA() != nil {
+				result += ","
+				continue
+			}
+			result = fmt.Sprintf("%s %s", result, joinType(joinPrefix, child))
+		case *parser.Join_constraintContext:
+			result = fmt.Sprintf("%s %s", result, c.Visit(child))
+		}
+	}
 
-		firstTable := ctx.Table_or_subquery(0)
+	return result
+}
 
-		var joinType string
-		tableText := firstTable.GetText()
+// joinType renders a join operator. prefix is a join keyword the parser
+// took as the alias of the preceding table, see joinKeywordAlias.
+func joinType(prefix string, op *parser.Join_operatorContext) string {
+	words := []string{prefix}
+	for _, child := range op.GetChildren() {
+		words = append(words, strings.ToUpper(child.(antlr.TerminalNode).GetText()))
+	}
 
-		if strings.Contains(tableText, "NATURAL") {
-			joinType = "NATURAL JOIN"
-		} else if strings.Contains(tableText, "CROSS") {
-			joinType = "CROSS JOIN"
-		} else if joinOp.LEFT_() != nil || strings.Contains(tableText, "LEFT") {
-			if joinOp.OUTER_() != nil {
-				joinType = "LEFT OUTER JOIN"
-			} else {
-				joinType = "LEFT JOIN"
-			}
-		} else {
-			joinType = "JOIN"
+	var joinWords []string
+	for _, word := range words {
+		// INNER is the default join type
+		if word != "" && word != "INNER" {
+			joinWords = append(joinWords, word)
 		}
+	}
+	return strings.Join(joinWords, " ")
+}
+
+// joinKeywordAlias returns the join keyword that the grammar parsed as the
+// alias of ctx. table_alias accepts any keyword, so in `a LEFT JOIN b` the
+// LEFT becomes the alias of a and the operator is only JOIN.
+func joinKeywordAlias(ctx *parser.Table_or_subqueryContext) string {
+	alias := ctx.Table_alias()
+	if alias == nil || alias.Any_name() == nil || alias.Any_name().Keyword() == nil {
+		return ""
+	}
 
-		var condition string
-		if constraint := ctx.Join_constraint(i); constraint != nil {
-			condition = c.Visit(constraint).(string)
+	keyword := alias.Any_name().Keyword()
+	switch {
+	case keyword.NATURAL_() != nil, keyword.LEFT_() != nil, keyword.RIGHT_() != nil,
+		keyword.FULL_() != nil, keyword.INNER_() != nil, keyword.CROSS_() != nil:
+		if _, ok := nextSibling(ctx).(*parser.Join_operatorContext); ok {
+			return strings.ToUpper(keyword.GetText())
 		}
-		result = fmt.Sprintf("%s %s %s %s", result, joinType, table, strings.TrimSpace(condition))
 	}
+	return ""
+}
 
-	return strings.TrimSpace(result)
+func nextSibling(ctx antlr.ParserRuleContext) antlr.Tree {
+	parent, ok := ctx.GetParent().(antlr.ParserRuleContext)
+	if !ok {
+		return nil
+	}
+	return childAfter(parent, ctx)
 }
 
 func (c *translatorCore) VisitJoin_constraint(ctx *parser.Join_constraintContext) any {
@@ -264,7 +803,58 @@ func (c *translatorCore) VisitJoin_constraint(ctx *parser.Join_constraintContext
 		return fmt.Sprintf("ON %s", c.Visit(ctx.Expr()))
 	}
 	if ctx.USING_() != nil {
-		return fmt.Sprintf("USING %s", ctx.GetText()[5:]) // skip "USING" keyword
+		var columns []string
+		for _, column := range ctx.AllColumn_name() {
+			columns = append(columns, c.name(column))
+		}
+		return fmt.Sprintf("USING (%s)", strings.Join(columns, ", "))
 	}
-	return ""
+	return c.unsupported(ctx)
+}
+
+func (c *translatorCore) VisitAny_name(ctx *parser.Any_nameContext) any {
+	if inner := ctx.Any_name(); inner != nil {
+		return c.Visit(inner)
+	}
+	return normalizeIdentifier(ctx.GetText())
+}
+
+// name translates one of the rules that only wrap any_name, such as
+// table_name or column_name.
+func (c *translatorCore) name(ctx antlr.ParserRuleContext) string {
+	if ctx == nil {
+		return ""
+	}
+	if anyName, ok := ctx.GetChild(0).(*parser.Any_nameContext); ok {
+		return c.visitString(anyName)
+	}
+	return c.unsupported(ctx)
+}
+
+// qualifiedName joins an optional schema name and a name with a dot.
+func (c *translatorCore) qualifiedName(schema, name antlr.ParserRuleContext) string {
+	if schema == nil {
+		return c.name(name)
+	}
+	return fmt.Sprintf("%s.%s", c.name(schema), c.name(name))
+}
+
+// normalizeIdentifier rewrites the `name` and [name] quoting SQLite accepts,
+// and string literals used as names, to the double quotes DuckDB expects.
+// Unquoted and double quoted identifiers are returned unchanged.
+func normalizeIdentifier(text string) string {
+	if text == "" || text[0] == '"' || !strings.ContainsRune("[`'", rune(text[0])) {
+		return text
+	}
+	return fmt.Sprintf(`"%s"`, strings.ReplaceAll(unquoteIdentifier(text), `"`, `""`))
+}
+
+// quoteIdentifier quotes a generated name when it is not a plain identifier.
+func quoteIdentifier(name string) string {
+	for i, r := range name {
+		if r != '_' && !unicode.IsLetter(r) && (i == 0 || !unicode.IsDigit(r)) {
+			return fmt.Sprintf(`"%s"`, strings.ReplaceAll(name, `"`, `""`))
+		}
+	}
+	return name
 }
@@ -42,7 +42,7 @@ func TestSimpleSelect(t *testing.T) {
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
@@ -81,7 +81,7 @@ func TestSelectWithWhere(t *testing.T) {
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
@@ -117,15 +117,65 @@ func TestSelectWithOrderBy(t *testing.T) {
 			input:    "SELECT * FROM users WHERE age > 18 ORDER BY name",
 			expected: "SELECT * FROM users WHERE age > 18 ORDER BY name",
 		},
+		{
+			name:     "order by expression with collation",
+			input:    "SELECT * FROM users ORDER BY lower( name ) COLLATE NOCASE desc, email COLLATE BINARY",
+			expected: "SELECT * FROM users ORDER BY lower(name) COLLATE NOCASE DESC, email",
+		},
+		{
+			name:     "order by with nulls",
+			input:    "SELECT * FROM users ORDER BY age ASC NULLS LAST, name NULLS FIRST",
+			expected: "SELECT * FROM users ORDER BY age ASC NULLS LAST, name NULLS FIRST",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSQLiteNullOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "ascending and descending",
+			input:    "SELECT * FROM users ORDER BY age, name ASC, id DESC",
+			expected: "SELECT * FROM users ORDER BY age NULLS FIRST, name ASC NULLS FIRST, id DESC NULLS LAST",
+		},
+		{
+			name:     "explicit nulls order is kept",
+			input:    "SELECT * FROM users ORDER BY age DESC NULLS FIRST",
+			expected: "SELECT * FROM users ORDER BY age DESC NULLS FIRST",
+		},
+		{
+			name:     "window ordering",
+			input:    "SELECT rank() OVER (ORDER BY score DESC) FROM players",
+			expected: "SELECT rank() OVER (ORDER BY score DESC NULLS LAST) FROM players",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			core := translatorCore{
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+				nullOrder:               true,
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
@@ -161,6 +211,31 @@ func TestSelectWithGroupBy(t *testing.T) {
 			input:    "SELECT department, COUNT(*) FROM employees GROUP BY department ORDER BY COUNT(*) DESC",
 			expected: "SELECT department, COUNT(*) FROM employees GROUP BY department ORDER BY COUNT(*) DESC",
 		},
+		{
+			name:     "group by expressions",
+			input:    "SELECT lower(name), count(*) FROM users GROUP BY lower( name ), age / 10",
+			expected: "SELECT lower(name), count(*) FROM users GROUP BY lower(name), age / 10",
+		},
+		{
+			name:     "having",
+			input:    "SELECT department, COUNT(*) AS n FROM employees GROUP BY department HAVING COUNT(*) > 5 AND max(salary) < 100",
+			expected: "SELECT department, COUNT(*) AS n FROM employees GROUP BY department HAVING COUNT(*) > 5 AND max(salary) < 100",
+		},
+		{
+			name:     "rowid in group by",
+			input:    "CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT); SELECT count(*) FROM t GROUP BY rowid % 2",
+			expected: "CREATE SEQUENCE t_id_seq;\nCREATE TABLE t (id BIGINT PRIMARY KEY DEFAULT nextval('t_id_seq'), v VARCHAR);\nSELECT count(*) FROM t GROUP BY id % 2",
+		},
+		{
+			name:     "distinct",
+			input:    "SELECT DISTINCT department FROM employees",
+			expected: "SELECT DISTINCT department FROM employees",
+		},
+		{
+			name:     "all",
+			input:    "select all department from employees",
+			expected: "SELECT ALL department FROM employees",
+		},
 	}
 
 	for _, tt := range tests {
@@ -169,7 +244,7 @@ func TestSelectWithGroupBy(t *testing.T) {
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
@@ -238,7 +313,7 @@ func TestSelectWithLimit(t *testing.T) {
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
@@ -284,6 +359,31 @@ func TestSelectWithJoin(t *testing.T) {
 			input:    "SELECT * FROM orders JOIN order_items USING (order_id)",
 			expected: "SELECT * FROM orders JOIN order_items USING (order_id)",
 		},
+		{
+			name:     "join using multiple columns",
+			input:    "SELECT * FROM orders JOIN order_items USING (order_id,shop_id)",
+			expected: "SELECT * FROM orders JOIN order_items USING (order_id, shop_id)",
+		},
+		{
+			name:     "join with table aliases",
+			input:    "SELECT u.name, o.amount FROM users u JOIN orders AS o ON u.id = o.user_id",
+			expected: "SELECT u.name, o.amount FROM users AS u JOIN orders AS o ON u.id = o.user_id",
+		},
+		{
+			name:     "chained joins keep their own types",
+			input:    "SELECT * FROM a CROSS JOIN b LEFT JOIN c ON b.id = c.id",
+			expected: "SELECT * FROM a CROSS JOIN b LEFT JOIN c ON b.id = c.id",
+		},
+		{
+			name:     "right and full joins",
+			input:    "SELECT * FROM a RIGHT JOIN b ON a.id = b.id FULL OUTER JOIN c ON b.id = c.id",
+			expected: "SELECT * FROM a RIGHT JOIN b ON a.id = b.id FULL OUTER JOIN c ON b.id = c.id",
+		},
+		{
+			name:     "comma separated tables",
+			input:    "SELECT * FROM users, orders WHERE users.id = orders.user_id",
+			expected: "SELECT * FROM users, orders WHERE users.id = orders.user_id",
+		},
 	}
 
 	for _, tt := range tests {
@@ -292,7 +392,7 @@ func TestSelectWithJoin(t *testing.T) {
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
@@ -341,12 +441,158 @@ func TestSelectWithSubqueries(t *testing.T) {
 				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
 			}
 
-			tree := createParseTree(tt.input)
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIdentifiersAndOperators(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "select without from",
+			input:    "SELECT 1",
+			expected: "SELECT 1",
+		},
+		{
+			name:     "bracket and backtick quoting",
+			input:    "SELECT [first name], `last``name` FROM [my users]",
+			expected: `SELECT "first name", "last`+"`"+`name" FROM "my users"`,
+		},
+		{
+			name:     "schema qualified names",
+			input:    "SELECT main.users.id FROM main.users",
+			expected: "SELECT main.users.id FROM main.users",
+		},
+		{
+			name:     "function call with several arguments",
+			input:    "SELECT coalesce(nickname, name) FROM users",
+			expected: "SELECT coalesce(nickname, name) FROM users",
+		},
+		{
+			name:     "count distinct",
+			input:    "SELECT count(DISTINCT name) FROM users",
+			expected: "SELECT count(DISTINCT name) FROM users",
+		},
+		{
+			name:     "multi keyword operator",
+			input:    "SELECT * FROM users WHERE deleted_at IS NOT NULL",
+			expected: "SELECT * FROM users WHERE deleted_at IS NOT NULL",
+		},
+		{
+			name:     "not exists",
+			input:    "SELECT * FROM users WHERE NOT EXISTS (SELECT 1 FROM bans WHERE bans.user_id = users.id)",
+			expected: "SELECT * FROM users WHERE NOT EXISTS (SELECT 1 FROM bans WHERE bans.user_id = users.id)",
+		},
+		{
+			name:     "parenthesised expression",
+			input:    "SELECT * FROM users WHERE (age > 18 OR admin = 1) AND active = 1",
+			expected: "SELECT * FROM users WHERE (age > 18 OR admin = 1) AND active = 1",
+		},
+		{
+			name:     "explain query plan",
+			input:    "EXPLAIN QUERY PLAN SELECT * FROM users",
+			expected: "EXPLAIN SELECT * FROM users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
 			got := core.Visit(tree).(string)
 
 			if got != tt.expected {
 				t.Errorf("got %q, want %q", got, tt.expected)
 			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics: %v", core.diagnostics)
+			}
+		})
+	}
+}
+
+func TestUnsupportedConstructs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mode     Mode
+		expected string
+		severity Severity
+		rule     string
+		start    Position
+	}{
+		{
+			name:     "strict table function",
+			input:    "SELECT value FROM generate_series(1, 10)",
+			mode:     Strict,
+			expected: "SELECT value FROM generate_series(1, 10)",
+			severity: SeverityError,
+			rule:     "table_or_subquery",
+			start:    Position{Line: 1, Column: 18},
+		},
+		{
+			name:     "lenient table function keeps whitespace",
+			input:    "SELECT value FROM generate_series( 1,\n  10 )",
+			mode:     Lenient,
+			expected: "SELECT value FROM generate_series( 1,\n  10 )",
+			severity: SeverityWarning,
+			rule:     "table_or_subquery",
+			start:    Position{Line: 1, Column: 18},
+		},
+		{
+			name:     "strict statement",
+			input:    "SELECT 1;\nCREATE VIRTUAL TABLE docs USING fts5(body)",
+			mode:     Strict,
+			expected: "SELECT 1;\nCREATE VIRTUAL TABLE docs USING fts5(body)",
+			severity: SeverityError,
+			rule:     "create_virtual_table_stmt",
+			start:    Position{Line: 2, Column: 0},
+		},
+		{
+			name:     "compound order by matching no column",
+			input:    "SELECT a FROM t UNION SELECT b FROM u ORDER BY c",
+			mode:     Lenient,
+			expected: "SELECT a FROM t UNION SELECT b FROM u ORDER BY c",
+			severity: SeverityError,
+			rule:     "expr",
+			start:    Position{Line: 1, Column: 47},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+				mode:                    tt.mode,
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != 1 {
+				t.Fatalf("got %d diagnostics, want 1: %v", len(core.diagnostics), core.diagnostics)
+			}
+
+			diag := core.diagnostics[0]
+			if diag.Severity != tt.severity || diag.Rule != tt.rule || diag.Start != tt.start {
+				t.Errorf("got %s in %s at %v, want %s in %s at %v",
+					diag.Severity, diag.Rule, diag.Start, tt.severity, tt.rule, tt.start)
+			}
 		})
 	}
 }
@@ -355,17 +601,35 @@ func TestStringConcatenation(t *testing.T) {
     tests := []struct {
         name     string
         input    string
+        concat   bool
         expected string
     }{
         {
             name:     "simple concatenation",
             input:    "SELECT first_name || ' ' || last_name FROM users",
-            expected: "SELECT concat(first_name, ' ', last_name) FROM users",
+            expected: "SELECT first_name || ' ' || last_name FROM users",
         },
         {
             name:     "concatenation in where",
             input:    "SELECT * FROM users WHERE first_name || last_name = 'JohnDoe'",
-            expected: "SELECT * FROM users WHERE concat(first_name, last_name) = 'JohnDoe'",
+            expected: "SELECT * FROM users WHERE first_name || last_name = 'JohnDoe'",
+        },
+        {
+            name:     "mixed with other operators",
+            input:    "SELECT a || (b || c) || upper(d || e), 'n' || n + 1 FROM t",
+            expected: "SELECT a || (b || c) || upper(d || e), ('n' || n) + 1 FROM t",
+        },
+        {
+            name:     "concat function",
+            input:    "SELECT first_name || ' ' || last_name FROM users WHERE a || b = 'ab'",
+            concat:   true,
+            expected: "SELECT concat(first_name, ' ', last_name) FROM users WHERE concat(a, b) = 'ab'",
+        },
+        {
+            name:     "concat function with parentheses and other operators",
+            input:    "SELECT a || (b || c) || upper(d || e), 'n' || n + 1 FROM t",
+            concat:   true,
+            expected: "SELECT concat(a, (concat(b, c)), upper(concat(d, e))), concat('n', n) + 1 FROM t",
         },
     }
 
@@ -373,9 +637,10 @@ func TestStringConcatenation(t *testing.T) {
         t.Run(tt.name, func(t *testing.T) {
             core := translatorCore{
                 BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+                concatFunction:          tt.concat,
             }
 
-            tree := createParseTree(tt.input)
+            tree := createParseTree(t, &core, tt.input)
             got := core.Visit(tree).(string)
 
             if got != tt.expected {
@@ -385,10 +650,232 @@ func TestStringConcatenation(t *testing.T) {
     }
 }
 
-func createParseTree(input string) antlr.ParseTree {
-	inputStream := antlr.NewInputStream(input)
-	lexer := parser.NewSQLiteLexer(inputStream)
-	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
-	p := parser.NewSQLiteParser(stream)
-	return p.Parse()
+func TestMultipleStatements(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "two statements",
+			input:    "SELECT * FROM users; SELECT * FROM orders",
+			expected: "SELECT * FROM users;\nSELECT * FROM orders",
+		},
+		{
+			name:     "trailing semicolon",
+			input:    "SELECT id FROM users; SELECT id FROM orders;",
+			expected: "SELECT id FROM users;\nSELECT id FROM orders",
+		},
+		{
+			name:     "empty statements",
+			input:    ";;SELECT * FROM users;;; SELECT * FROM orders;;",
+			expected: "SELECT * FROM users;\nSELECT * FROM orders",
+		},
+		{
+			name:     "many statements keep order",
+			input:    "SELECT a FROM t1; SELECT b FROM t2; SELECT c FROM t3",
+			expected: "SELECT a FROM t1;\nSELECT b FROM t2;\nSELECT c FROM t3",
+		},
+		{
+			name:     "empty script",
+			input:    "",
+			expected: "",
+		},
+		{
+			name:     "only semicolons",
+			input:    "; ;;\n;",
+			expected: "",
+		},
+		{
+			name:     "semicolons around a comment",
+			input:    ";\n-- nothing\n;",
+			expected: "-- nothing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// createParseTree parses input as Translate does and gives core the tokens
+// of the tree.
+func createParseTree(t *testing.T, core *translatorCore, input string) antlr.ParseTree {
+	t.Helper()
+	tree, p, err := parse(input)
+	if err != nil {
+		t.Fatalf("parse %q: %v", input, err)
+	}
+	core.tokens, _ = p.GetTokenStream().(*antlr.CommonTokenStream)
+	return tree
+}
+
+func TestCommonTableExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "with",
+			input:    "WITH recent AS (SELECT * FROM orders WHERE ts > ?) SELECT count(*) FROM recent",
+			expected: "WITH recent AS (SELECT * FROM orders WHERE ts > ?) SELECT count(*) FROM recent",
+		},
+		{
+			name:     "several tables with column lists",
+			input:    "WITH a(x) AS (SELECT 1), [b] (y, z) AS (SELECT x, x FROM a) SELECT * FROM b",
+			expected: "WITH a (x) AS (SELECT 1), \"b\" (y, z) AS (SELECT x, x FROM a) SELECT * FROM b",
+		},
+		{
+			name:     "recursive",
+			input:    "WITH RECURSIVE t(n) AS (SELECT 1) SELECT n FROM t ORDER BY n LIMIT 5",
+			expected: "WITH RECURSIVE t (n) AS (SELECT 1) SELECT n FROM t ORDER BY n LIMIT 5",
+		},
+		{
+			name:     "nested in a subquery",
+			input:    "SELECT * FROM (WITH t AS (SELECT 1 AS v) SELECT v FROM t) AS s",
+			expected: "SELECT * FROM (WITH t AS (SELECT 1 AS v) SELECT v FROM t) AS s",
+		},
+		{
+			name:     "insert",
+			input:    "WITH src AS (SELECT id FROM staging) INSERT INTO target (id) SELECT id FROM src",
+			expected: "WITH src AS (SELECT id FROM staging) INSERT INTO target (id) SELECT id FROM src",
+		},
+		{
+			name:     "update",
+			input:    "WITH stale AS (SELECT id FROM items WHERE ts < 0) UPDATE items SET stale = 1 WHERE id IN (SELECT id FROM stale) RETURNING id",
+			expected: "WITH stale AS (SELECT id FROM items WHERE ts < 0) UPDATE items SET stale = 1 WHERE id IN (SELECT id FROM stale) RETURNING id",
+		},
+		{
+			name:     "delete",
+			input:    "WITH RECURSIVE old(id) AS (SELECT id FROM items) DELETE FROM items WHERE id IN (SELECT id FROM old)",
+			expected: "WITH RECURSIVE old (id) AS (SELECT id FROM items) DELETE FROM items WHERE id IN (SELECT id FROM old)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics %v", core.diagnostics)
+			}
+		})
+	}
+}
+
+func TestCompoundSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "union",
+			input:    "SELECT a FROM t UNION SELECT b FROM u",
+			expected: "SELECT a FROM t UNION SELECT b FROM u",
+		},
+		{
+			name:     "every operator",
+			input:    "SELECT a FROM t UNION ALL SELECT a FROM u INTERSECT SELECT a FROM v except SELECT a FROM w",
+			expected: "SELECT a FROM t UNION ALL SELECT a FROM u INTERSECT SELECT a FROM v EXCEPT SELECT a FROM w",
+		},
+		{
+			name:     "order by number and alias",
+			input:    "SELECT id, name AS label FROM t UNION SELECT id, title FROM u ORDER BY label, 1 DESC LIMIT 10 OFFSET 5",
+			expected: "SELECT id, name AS label FROM t UNION SELECT id, title FROM u ORDER BY label, 1 DESC LIMIT 10 OFFSET 5",
+		},
+		{
+			name:     "order by qualified column",
+			input:    "SELECT t.id, t.name FROM t UNION ALL SELECT u.id, u.title FROM u ORDER BY t.name",
+			expected: "SELECT t.id, t.name FROM t UNION ALL SELECT u.id, u.title FROM u ORDER BY 2",
+		},
+		{
+			name:     "order by expression of a later core",
+			input:    "SELECT a FROM t UNION SELECT lower(b) FROM u ORDER BY lower( b ) ASC",
+			expected: "SELECT a FROM t UNION SELECT lower(b) FROM u ORDER BY 1 ASC",
+		},
+		{
+			name:     "order by names of a later core",
+			input:    "SELECT a FROM t UNION SELECT b AS x FROM u ORDER BY x; SELECT a FROM t UNION SELECT b AS x FROM u ORDER BY b DESC",
+			expected: "SELECT a FROM t UNION SELECT b AS x FROM u ORDER BY 1;\nSELECT a FROM t UNION SELECT b AS x FROM u ORDER BY 1 DESC",
+		},
+		{
+			name:     "order by names of the first core",
+			input:    "SELECT a AS k, b FROM t UNION SELECT c, b AS a FROM u ORDER BY k, b, a",
+			expected: "SELECT a AS k, b FROM t UNION SELECT c, b AS a FROM u ORDER BY k, b, 1",
+		},
+		{
+			name:     "order by a column of values",
+			input:    "SELECT a, b FROM t UNION VALUES (1, 2) ORDER BY column2",
+			expected: "SELECT a, b FROM t UNION VALUES (1, 2) ORDER BY 2",
+		},
+		{
+			name:     "nested simple select keeps its order by",
+			input:    "SELECT a FROM (SELECT a FROM t ORDER BY t.a) UNION SELECT a FROM u",
+			expected: "SELECT a FROM (SELECT a FROM t ORDER BY t.a) UNION SELECT a FROM u",
+		},
+		{
+			name:     "chained after table names",
+			input:    "SELECT a FROM t UNION SELECT a FROM u, v INTERSECT SELECT a FROM (SELECT 1 AS a) EXCEPT SELECT a FROM w",
+			expected: "SELECT a FROM t UNION SELECT a FROM u, v INTERSECT SELECT a FROM (SELECT 1 AS a) EXCEPT SELECT a FROM w",
+		},
+		{
+			name:     "insert from a compound followed by a statement",
+			input:    "INSERT INTO x SELECT a FROM t EXCEPT SELECT a FROM u; SELECT 1 FROM x",
+			expected: "INSERT INTO x SELECT a FROM t EXCEPT SELECT a FROM u;\nSELECT 1 FROM x",
+		},
+		{
+			name:     "values",
+			input:    "VALUES (1, 'a'), (2, 'b'); SELECT * FROM (VALUES (1, 2)) AS v",
+			expected: "VALUES (1, 'a'), (2, 'b');\nSELECT * FROM (VALUES (1, 2)) AS v",
+		},
+		{
+			name:     "values in a compound",
+			input:    "SELECT a FROM t UNION ALL VALUES (1), (2) ORDER BY 1",
+			expected: "SELECT a FROM t UNION ALL VALUES (1), (2) ORDER BY 1",
+		},
+		{
+			name:     "recursive common table",
+			input:    "WITH RECURSIVE cnt(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM cnt WHERE x < 10) SELECT x FROM cnt",
+			expected: "WITH RECURSIVE cnt (x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM cnt WHERE x < 10) SELECT x FROM cnt",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics %v", core.diagnostics)
+			}
+		})
+	}
 }
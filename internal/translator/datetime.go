@@ -0,0 +1,309 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sql-translator/internal/parser"
+)
+
+// SQLite's date and time functions take a time value followed by modifiers
+// and return text. They are translated by turning the time value into a
+// DuckDB TIMESTAMP holding UTC, applying each modifier to that expression and
+// formatting the result with strftime, so the functions still return text.
+
+func init() {
+	registerFunctions(map[string]functionTranslator{
+		"date":      formattedTime("%Y-%m-%d", "%Y-%m-%d"),
+		"time":      formattedTime("%H:%M:%S", "%H:%M:%S.%g"),
+		"datetime":  formattedTime("%Y-%m-%d %H:%M:%S", "%Y-%m-%d %H:%M:%S.%g"),
+		"julianday": (*translatorCore).julianday,
+		"unixepoch": (*translatorCore).unixepoch,
+		"strftime":  (*translatorCore).strftime,
+	})
+}
+
+// timeValue is a translated time value with its modifiers applied.
+type timeValue struct {
+	// expr is a DuckDB TIMESTAMP expression
+	expr string
+	// subsec is set by the subsec modifier, which asks for milliseconds in
+	// the output
+	subsec bool
+	// floor is set by the floor modifier, which makes SQLite's month
+	// arithmetic clamp like DuckDB's does
+	floor bool
+}
+
+func formattedTime(format, subsecFormat string) functionTranslator {
+	return func(c *translatorCore, call *parser.ExprContext) string {
+		value := c.timeValue(call.AllExpr())
+		if value.subsec {
+			return fmt.Sprintf("strftime(%s, '%s')", value.expr, subsecFormat)
+		}
+		return fmt.Sprintf("strftime(%s, '%s')", value.expr, format)
+	}
+}
+
+func (c *translatorCore) julianday(call *parser.ExprContext) string {
+	return julianDay(c.timeValue(call.AllExpr()).expr)
+}
+
+func (c *translatorCore) unixepoch(call *parser.ExprContext) string {
+	value := c.timeValue(call.AllExpr())
+	if value.subsec {
+		return fmt.Sprintf("epoch(%s)", value.expr)
+	}
+	return unixSeconds(value.expr)
+}
+
+func julianDay(timestamp string) string {
+	return fmt.Sprintf("(epoch(%s) / 86400.0 + 2440587.5)", timestamp)
+}
+
+func unixSeconds(timestamp string) string {
+	return fmt.Sprintf("CAST(floor(epoch(%s)) AS BIGINT)", timestamp)
+}
+
+// strftimeFormats maps the strftime conversions of SQLite to DuckDB's.
+// %s and %J have no DuckDB conversion and are computed separately.
+var strftimeFormats = map[byte]string{
+	'd': "%d",
+	'f': "%S.%g",
+	'F': "%Y-%m-%d",
+	'G': "%G",
+	'H': "%H",
+	'I': "%I",
+	'j': "%j",
+	'm': "%m",
+	'M': "%M",
+	'p': "%p",
+	'R': "%H:%M",
+	'S': "%S",
+	'T': "%H:%M:%S",
+	'u': "%u",
+	'U': "%U",
+	'V': "%V",
+	'w': "%w",
+	'W': "%W",
+	'Y': "%Y",
+	'%': "%%",
+}
+
+func (c *translatorCore) strftime(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) == 0 {
+		c.unsupportedf(call, "strftime without a format cannot be translated")
+		return c.sourceText(call)
+	}
+
+	value := c.timeValue(args[1:])
+	format, ok := stringLiteral(args[0])
+	if !ok {
+		c.unsupportedf(args[0], "strftime format %s is not a string literal, its conversions cannot be translated", c.sourceText(args[0]))
+		return fmt.Sprintf("strftime(%s, %s)", value.expr, c.visitString(args[0]))
+	}
+
+	// The format is cut at %s and %J, the parts are concatenated
+	var parts []string
+	var pending strings.Builder
+	flush := func() {
+		if pending.Len() > 0 {
+			parts = append(parts, fmt.Sprintf("strftime(%s, %s)", value.expr, quoteString(pending.String())))
+			pending.Reset()
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			pending.WriteByte(format[i])
+			continue
+		}
+		i++
+
+		switch conversion := format[i]; conversion {
+		case 's':
+			flush()
+			parts = append(parts, fmt.Sprintf("CAST(%s AS VARCHAR)", unixSeconds(value.expr)))
+		case 'J':
+			flush()
+			parts = append(parts, fmt.Sprintf("CAST(%s AS VARCHAR)", julianDay(value.expr)))
+		default:
+			if mapped, ok := strftimeFormats[conversion]; ok {
+				pending.WriteString(mapped)
+			} else {
+				c.unsupportedf(args[0], "strftime conversion %%%c has no DuckDB equivalent", conversion)
+			}
+		}
+	}
+	flush()
+
+	switch len(parts) {
+	case 0:
+		return "''"
+	case 1:
+		return parts[0]
+	}
+	return fmt.Sprintf("(%s)", strings.Join(parts, " || "))
+}
+
+var (
+	numberModifier  = regexp.MustCompile(`^([+-]?)(\d+(?:\.\d*)?|\.\d+)\s*(year|month|day|hour|minute|second)s?$`)
+	offsetModifier  = regexp.MustCompile(`^([+-])(\d\d:\d\d(?::\d\d(?:\.\d+)?)?)$`)
+	weekdayModifier = regexp.MustCompile(`^weekday\s+([0-6])$`)
+	startModifier   = regexp.MustCompile(`^start\s+of\s+(year|month|day)$`)
+)
+
+// secondsPer converts fractional amounts of the units DuckDB only accepts
+// whole numbers of.
+var secondsPer = map[string]float64{
+	"day":    86400,
+	"hour":   3600,
+	"minute": 60,
+}
+
+// timeValue translates the arguments of a date and time function that
+// follow the format: the time value and its modifiers. Only string literal
+// modifiers can be translated.
+func (c *translatorCore) timeValue(args []parser.IExprContext) timeValue {
+	var modifiers []string
+	var modifierArgs []parser.IExprContext
+	var numeric string
+	if len(args) > 1 {
+		for _, arg := range args[1:] {
+			modifier, ok := stringLiteral(arg)
+			if !ok {
+				c.unsupportedf(arg, "date modifier %s is not a string literal and cannot be translated", c.sourceText(arg))
+				continue
+			}
+			modifier = strings.ToLower(strings.TrimSpace(modifier))
+			if modifier == "unixepoch" || modifier == "julianday" || modifier == "auto" {
+				numeric = modifier
+			}
+			modifiers = append(modifiers, modifier)
+			modifierArgs = append(modifierArgs, arg)
+		}
+	}
+
+	value := timeValue{expr: c.timestamp(args, numeric)}
+	for _, modifier := range modifiers {
+		value.floor = value.floor || modifier == "floor"
+	}
+	for i, modifier := range modifiers {
+		c.applyModifier(&value, modifier, modifierArgs[i])
+	}
+	return value
+}
+
+// timeOnly matches a time value without a date, which SQLite reads as a
+// time on 2000-01-01.
+var timeOnly = regexp.MustCompile(`^\s*\d\d:\d\d`)
+
+// timestamp translates a time value into a TIMESTAMP expression. numeric
+// is the modifier saying how the value is read, if any. Without one values
+// known to be numbers are read as Julian days, anything else is read as
+// text. Text that is not a time value gives NULL, as in SQLite.
+func (c *translatorCore) timestamp(args []parser.IExprContext, numeric string) string {
+	const now = "(current_timestamp AT TIME ZONE 'UTC')"
+	if len(args) == 0 {
+		return now
+	}
+
+	arg := args[0]
+	if text, ok := stringLiteral(arg); ok && strings.EqualFold(strings.TrimSpace(text), "now") {
+		return now
+	}
+
+	isNumber := c.numberKind(arg) != unknownNumber
+	switch {
+	case numeric == "unixepoch":
+		return fmt.Sprintf("(to_timestamp(%s) AT TIME ZONE 'UTC')", c.visitString(arg))
+	case numeric == "auto":
+		c.unsupportedf(arg, "date modifier 'auto' cannot be translated, use 'unixepoch' or 'julianday'")
+	case numeric == "julianday" || isNumber:
+		return fmt.Sprintf("(to_timestamp((%s - 2440587.5) * 86400) AT TIME ZONE 'UTC')", c.operand(arg, duckDBAdditive))
+	}
+	if text, ok := stringLiteral(arg); ok {
+		if timeOnly.MatchString(text) {
+			text = "2000-01-01 " + strings.TrimSpace(text)
+		}
+		return fmt.Sprintf("TRY_CAST(%s AS TIMESTAMP)", quoteString(text))
+	}
+	if numeric == "" && !c.textValue(arg) {
+		c.warnf(arg, "time value %s read as text, SQLite reads numbers as Julian days and DuckDB gives NULL for them, add a 'julianday' or 'unixepoch' modifier if it holds numbers", c.sourceText(arg))
+	}
+	return fmt.Sprintf(`TRY_CAST(regexp_replace(CAST(%s AS VARCHAR), '^\s*(\d\d:\d\d)', '2000-01-01 \1') AS TIMESTAMP)`, c.visitString(arg))
+}
+
+// textValue tells whether expr is known to give text: a column or CAST of
+// text affinity, or a date and time function.
+func (c *translatorCore) textValue(expr parser.IExprContext) bool {
+	if affinity, ok := c.declaredAffinity(expr); ok {
+		return affinity == textAffinity
+	}
+	if name := expr.Function_name(); name != nil {
+		switch strings.ToLower(unquoteIdentifier(name.GetText())) {
+		case "date", "time", "datetime", "strftime":
+			return true
+		}
+	}
+	return false
+}
+
+// applyModifier applies one modifier to value.
+func (c *translatorCore) applyModifier(value *timeValue, modifier string, arg parser.IExprContext) {
+	if m := numberModifier.FindStringSubmatch(modifier); m != nil {
+		sign, amount, unit := m[1], m[2], m[3]
+		if strings.Contains(amount, ".") {
+			n, _ := strconv.ParseFloat(amount, 64)
+			if factor, ok := secondsPer[unit]; ok {
+				amount, unit = strconv.FormatFloat(n*factor, 'f', -1, 64), "second"
+			} else if unit != "second" {
+				c.unsupportedf(arg, "date modifier '%s' has no DuckDB equivalent, intervals take whole months and years", modifier)
+				return
+			}
+		}
+		if (unit == "month" || unit == "year") && !value.floor {
+			c.warnf(arg, "date modifier '%s' translated to interval arithmetic, DuckDB clamps to the end of the month where SQLite overflows into the next one", modifier)
+		}
+		if sign == "" {
+			sign = "+"
+		}
+		value.expr = fmt.Sprintf("(%s %s INTERVAL '%s %s')", value.expr, sign, amount, unit)
+		return
+	}
+
+	if m := offsetModifier.FindStringSubmatch(modifier); m != nil {
+		value.expr = fmt.Sprintf("(%s %s INTERVAL '%s')", value.expr, m[1], m[2])
+		return
+	}
+
+	if m := startModifier.FindStringSubmatch(modifier); m != nil {
+		value.expr = fmt.Sprintf("date_trunc('%s', %s)", m[1], value.expr)
+		return
+	}
+
+	if m := weekdayModifier.FindStringSubmatch(modifier); m != nil {
+		// Moves forward to the given day of the week, Sunday being 0, unless
+		// the date already falls on it
+		value.expr = fmt.Sprintf("(%[1]s + to_days(CAST((%[2]s - dayofweek(%[1]s) + 7) %% 7 AS INTEGER)))", value.expr, m[1])
+		return
+	}
+
+	switch modifier {
+	case "unixepoch", "julianday", "auto":
+		// Already used to read the time value
+	case "localtime":
+		value.expr = fmt.Sprintf("CAST(%s AT TIME ZONE 'UTC' AS TIMESTAMP)", value.expr)
+	case "utc":
+		value.expr = fmt.Sprintf("(CAST(%s AS TIMESTAMPTZ) AT TIME ZONE 'UTC')", value.expr)
+	case "subsec", "subsecond":
+		value.subsec = true
+	case "floor":
+		// See the month and year amounts above
+	default:
+		c.unsupportedf(arg, "date modifier '%s' has no DuckDB equivalent", modifier)
+	}
+}
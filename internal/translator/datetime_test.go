@@ -0,0 +1,162 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestDateTimeFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "date now",
+			input:    "SELECT date('now')",
+			expected: "SELECT strftime((current_timestamp AT TIME ZONE 'UTC'), '%Y-%m-%d')",
+		},
+		{
+			name:     "datetime without arguments",
+			input:    "SELECT datetime()",
+			expected: "SELECT strftime((current_timestamp AT TIME ZONE 'UTC'), '%Y-%m-%d %H:%M:%S')",
+		},
+		{
+			name:     "time without a date",
+			input:    "SELECT time('12:00:00'), datetime(' 08:30', '+1 hour')",
+			expected: "SELECT strftime(TRY_CAST('2000-01-01 12:00:00' AS TIMESTAMP), '%H:%M:%S'), strftime((TRY_CAST('2000-01-01 08:30' AS TIMESTAMP) + INTERVAL '1 hour'), '%Y-%m-%d %H:%M:%S')",
+		},
+		{
+			name:     "invalid time value",
+			input:    "SELECT date('not a date'), julianday('2024-13-45')",
+			expected: "SELECT strftime(TRY_CAST('not a date' AS TIMESTAMP), '%Y-%m-%d'), (epoch(TRY_CAST('2024-13-45' AS TIMESTAMP)) / 86400.0 + 2440587.5)",
+		},
+		{
+			name:     "time of a column with subsec",
+			input:    "SELECT time(created, 'subsec') FROM t",
+			expected: "SELECT strftime(TRY_CAST(regexp_replace(CAST(created AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP), '%H:%M:%S.%g') FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "interval modifiers",
+			input:    "SELECT date(d, '+1 day', '-2 hours', '+1.5 minutes', '+01:30') FROM t",
+			expected: "SELECT strftime(((((TRY_CAST(regexp_replace(CAST(d AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP) + INTERVAL '1 day') - INTERVAL '2 hour') + INTERVAL '90 second') + INTERVAL '01:30'), '%Y-%m-%d') FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "start of month and weekday",
+			input:    "SELECT date('2024-05-17', 'start of month', '+1 month', 'floor', 'weekday 0')",
+			expected: "SELECT strftime(((date_trunc('month', TRY_CAST('2024-05-17' AS TIMESTAMP)) + INTERVAL '1 month') + to_days(CAST((0 - dayofweek((date_trunc('month', TRY_CAST('2024-05-17' AS TIMESTAMP)) + INTERVAL '1 month')) + 7) % 7 AS INTEGER))), '%Y-%m-%d')",
+		},
+		{
+			name:     "month arithmetic",
+			input:    "SELECT date(d, '+1 month') FROM t",
+			expected: "SELECT strftime((TRY_CAST(regexp_replace(CAST(d AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP) + INTERVAL '1 month'), '%Y-%m-%d') FROM t",
+			warnings: 2,
+		},
+		{
+			name:     "unixepoch and localtime",
+			input:    "SELECT datetime(ts, 'unixepoch', 'localtime') FROM t",
+			expected: "SELECT strftime(CAST((to_timestamp(ts) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS TIMESTAMP), '%Y-%m-%d %H:%M:%S') FROM t",
+		},
+		{
+			name:     "julian day number",
+			input:    "SELECT date(2460000.5)",
+			expected: "SELECT strftime((to_timestamp((2460000.5 - 2440587.5) * 86400) AT TIME ZONE 'UTC'), '%Y-%m-%d')",
+		},
+		{
+			name:     "numeric columns",
+			input:    "CREATE TABLE ev (jd REAL, n INTEGER, s TEXT); SELECT date(jd), datetime(n + 1), time(s), date(date(s)) FROM ev",
+			expected: "CREATE TABLE ev (jd DOUBLE, n BIGINT, s VARCHAR);\nSELECT strftime((to_timestamp((jd - 2440587.5) * 86400) AT TIME ZONE 'UTC'), '%Y-%m-%d'), strftime((to_timestamp((n + 1 - 2440587.5) * 86400) AT TIME ZONE 'UTC'), '%Y-%m-%d %H:%M:%S'), " +
+				"strftime(TRY_CAST(regexp_replace(CAST(s AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP), '%H:%M:%S'), strftime(TRY_CAST(regexp_replace(CAST(strftime(TRY_CAST(regexp_replace(CAST(s AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP), '%Y-%m-%d') AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP), '%Y-%m-%d') FROM ev",
+		},
+		{
+			name:     "julianday",
+			input:    "SELECT julianday(a) - julianday(b) FROM t",
+			expected: "SELECT (epoch(TRY_CAST(regexp_replace(CAST(a AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP)) / 86400.0 + 2440587.5) - (epoch(TRY_CAST(regexp_replace(CAST(b AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP)) / 86400.0 + 2440587.5) FROM t",
+			warnings: 2,
+		},
+		{
+			name:     "unixepoch",
+			input:    "SELECT unixepoch(), unixepoch(d, 'utc', 'subsec') FROM t",
+			expected: "SELECT CAST(floor(epoch((current_timestamp AT TIME ZONE 'UTC'))) AS BIGINT), epoch((CAST(TRY_CAST(regexp_replace(CAST(d AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP) AS TIMESTAMPTZ) AT TIME ZONE 'UTC')) FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "strftime",
+			input:    "SELECT strftime('%Y-%m-%dT%H:%M:%f 100%%', d) FROM t",
+			expected: "SELECT strftime(TRY_CAST(regexp_replace(CAST(d AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP), '%Y-%m-%dT%H:%M:%S.%g 100%%') FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "strftime unix seconds",
+			input:    "SELECT strftime('%s', 'now'), strftime('day %j at %s', d) FROM t",
+			expected: "SELECT CAST(CAST(floor(epoch((current_timestamp AT TIME ZONE 'UTC'))) AS BIGINT) AS VARCHAR), (strftime(TRY_CAST(regexp_replace(CAST(d AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP), 'day %j at ') || CAST(CAST(floor(epoch(TRY_CAST(regexp_replace(CAST(d AS VARCHAR), '^\\s*(\\d\\d:\\d\\d)', '2000-01-01 \\1') AS TIMESTAMP))) AS BIGINT) AS VARCHAR)) FROM t",
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestDateTimeFunctionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{
+			name:    "unknown modifier",
+			input:   "SELECT date(d, 'ceiling') FROM t",
+			message: "date modifier 'ceiling' has no DuckDB equivalent",
+		},
+		{
+			name:    "modifier from a column",
+			input:   "SELECT date(d, m) FROM t",
+			message: "date modifier m is not a string literal and cannot be translated",
+		},
+		{
+			name:    "fractional months",
+			input:   "SELECT date(d, '+1.5 months') FROM t",
+			message: "date modifier '+1.5 months' has no DuckDB equivalent, intervals take whole months and years",
+		},
+		{
+			name:    "strftime conversion",
+			input:   "SELECT strftime('%e', d) FROM t",
+			message: "strftime conversion %e has no DuckDB equivalent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			core.Visit(createParseTree(t, &core, tt.input))
+
+			errs := core.diagnostics.errors()
+			if len(errs) != 1 || errs[0].Msg != tt.message {
+				t.Errorf("got diagnostics %v, want error %q", core.diagnostics, tt.message)
+			}
+		})
+	}
+}
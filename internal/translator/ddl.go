@@ -0,0 +1,349 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+func (c *translatorCore) VisitCreate_table_stmt(ctx *parser.Create_table_stmtContext) any {
+	if ctx.WITHOUT_() != nil {
+		c.warnf(ctx, "WITHOUT ROWID dropped, DuckDB tables have no rowid storage choice")
+	}
+
+	temporary := ctx.TEMP_() != nil || ctx.TEMPORARY_() != nil
+	ifNotExists := ctx.IF_() != nil
+	table := c.qualifiedName(ctx.Schema_name(), ctx.Table_name())
+
+	query := createPrefix("TABLE", temporary, ifNotExists)
+	query = fmt.Sprintf("%s %s", query, table)
+
+	if ctx.AS_() != nil {
+		return fmt.Sprintf("%s AS %s", query, c.Visit(ctx.Select_stmt()))
+	}
+
+	schema := &tableSchema{columns: make(map[string]string)}
+	for _, column := range ctx.AllColumn_def() {
+		var declared string
+		if typeName := column.Type_name(); typeName != nil {
+			declared = typeName.GetText()
+		}
+		schema.columns[strings.ToLower(unquoteIdentifier(column.Column_name().GetText()))] = declared
+	}
+	var rowidAlias parser.IColumn_defContext
+	if ctx.WITHOUT_() == nil {
+		rowidAlias = rowidAliasColumn(ctx)
+	}
+
+	var definitions []string
+	var sequence string
+	for _, column := range ctx.AllColumn_def() {
+		definition := c.visitString(column)
+		if column == rowidAlias {
+			// DuckDB has no rowid alias, a sequence provides the automatic values
+			sequence = c.rowidSequence(ctx, column)
+			c.warnf(column, "sequence %s starts at 1, restart it past the largest %s after copying existing rows", sequence, c.name(column.Column_name()))
+			definition = fmt.Sprintf("%s DEFAULT nextval('%s')", definition, sequence)
+			schema.rowidAlias = c.name(column.Column_name())
+		}
+		definitions = append(definitions, definition)
+	}
+	for _, constraint := range ctx.AllTable_constraint() {
+		definitions = append(definitions, c.visitString(constraint))
+	}
+
+	c.defineTable(ctx.Table_name().GetText(), schema)
+	query = fmt.Sprintf("%s (%s)", query, strings.Join(definitions, ", "))
+
+	if sequence != "" {
+		createSequence := createPrefix("SEQUENCE", temporary, ifNotExists)
+		return fmt.Sprintf("%s %s;\n%s", createSequence, sequence, query)
+	}
+	return query
+}
+
+// createPrefix builds "CREATE [TEMPORARY] <object> [IF NOT EXISTS]".
+func createPrefix(object string, temporary, ifNotExists bool) string {
+	query := "CREATE"
+	if temporary {
+		query += " TEMPORARY"
+	}
+	query = fmt.Sprintf("%s %s", query, object)
+	if ifNotExists {
+		query += " IF NOT EXISTS"
+	}
+	return query
+}
+
+// rowidSequence names the sequence backing the rowid alias of a table,
+// <table>_<column>_seq in the schema of the table.
+func (c *translatorCore) rowidSequence(ctx *parser.Create_table_stmtContext, column parser.IColumn_defContext) string {
+	table := unquoteIdentifier(ctx.Table_name().GetText())
+	name := fmt.Sprintf("%s_%s_seq", table, unquoteIdentifier(column.Column_name().GetText()))
+
+	if schema := ctx.Schema_name(); schema != nil {
+		return fmt.Sprintf("%s.%s", c.name(schema), quoteIdentifier(name))
+	}
+	return quoteIdentifier(name)
+}
+
+// rowidAliasColumn returns the column that becomes an alias for the rowid,
+// which in SQLite is a column declared exactly INTEGER that is the primary
+// key on its own. Descending column level keys are the documented exception.
+func rowidAliasColumn(ctx *parser.Create_table_stmtContext) parser.IColumn_defContext {
+	isInteger := func(column parser.IColumn_defContext) bool {
+		typeName := column.Type_name()
+		return typeName != nil && len(typeName.AllName()) == 1 && len(typeName.AllSigned_number()) == 0 &&
+			strings.EqualFold(typeName.GetText(), "INTEGER")
+	}
+
+	for _, column := range ctx.AllColumn_def() {
+		for _, constraint := range column.AllColumn_constraint() {
+			if constraint.PRIMARY_() == nil {
+				continue
+			}
+			if isInteger(column) && (constraint.Asc_desc() == nil || constraint.Asc_desc().DESC_() == nil) {
+				return column
+			}
+			return nil
+		}
+	}
+
+	for _, constraint := range ctx.AllTable_constraint() {
+		if constraint.PRIMARY_() == nil || len(constraint.AllIndexed_column()) != 1 {
+			continue
+		}
+		key := constraint.Indexed_column(0).Column_name()
+		if key == nil {
+			return nil
+		}
+		for _, column := range ctx.AllColumn_def() {
+			if strings.EqualFold(unquoteIdentifier(column.Column_name().GetText()), unquoteIdentifier(key.GetText())) && isInteger(column) {
+				return column
+			}
+		}
+	}
+	return nil
+}
+
+func (c *translatorCore) VisitColumn_def(ctx *parser.Column_defContext) any {
+	column := fmt.Sprintf("%s %s", c.name(ctx.Column_name()), c.columnType(ctx.Type_name()))
+
+	for _, constraint := range ctx.AllColumn_constraint() {
+		if translated := c.visitString(constraint); translated != "" {
+			column = fmt.Sprintf("%s %s", column, translated)
+		}
+	}
+
+	return column
+}
+
+func (c *translatorCore) VisitColumn_constraint(ctx *parser.Column_constraintContext) any {
+	var constraint string
+	switch {
+	case ctx.PRIMARY_() != nil:
+		// AUTOINCREMENT only means something for the rowid alias, which is
+		// given a sequence by the table. ASC/DESC only orders the index.
+		constraint = "PRIMARY KEY"
+	case ctx.NULL_() != nil && ctx.NOT_() != nil:
+		constraint = "NOT NULL"
+	case ctx.NULL_() != nil:
+		// NULL is the default and only accepted for compatibility
+		c.conflictClause(ctx.Conflict_clause())
+		return ""
+	case ctx.UNIQUE_() != nil:
+		constraint = "UNIQUE"
+	case ctx.CHECK_() != nil:
+		constraint = fmt.Sprintf("CHECK (%s)", c.Visit(ctx.Expr()))
+	case ctx.DEFAULT_() != nil:
+		constraint = fmt.Sprintf("DEFAULT %s", c.defaultValue(ctx))
+	case ctx.COLLATE_() != nil:
+		collation, ok := c.collation(ctx.Collation_name())
+		if !ok {
+			return ""
+		}
+		constraint = fmt.Sprintf("COLLATE %s", collation)
+	case ctx.Foreign_key_clause() != nil:
+		constraint = c.visitString(ctx.Foreign_key_clause())
+	case ctx.AS_() != nil:
+		constraint = fmt.Sprintf("GENERATED ALWAYS AS (%s)", c.Visit(ctx.Expr()))
+		if ctx.STORED_() != nil {
+			c.warnf(ctx, "STORED generated column made VIRTUAL, DuckDB only supports virtual generated columns")
+		}
+	default:
+		return c.unsupported(ctx)
+	}
+
+	c.conflictClause(ctx.Conflict_clause())
+
+	if ctx.CONSTRAINT_() != nil {
+		return fmt.Sprintf("CONSTRAINT %s %s", c.name(ctx.Name()), constraint)
+	}
+	return constraint
+}
+
+func (c *translatorCore) defaultValue(ctx *parser.Column_constraintContext) string {
+	switch {
+	case ctx.Signed_number() != nil:
+		return ctx.Signed_number().GetText()
+	case ctx.Literal_value() != nil:
+		return c.visitString(ctx.Literal_value())
+	}
+	return fmt.Sprintf("(%s)", c.Visit(ctx.Expr()))
+}
+
+func (c *translatorCore) VisitTable_constraint(ctx *parser.Table_constraintContext) any {
+	var constraint string
+	switch {
+	case ctx.PRIMARY_() != nil:
+		constraint = fmt.Sprintf("PRIMARY KEY (%s)", c.indexedColumns(ctx.AllIndexed_column()))
+	case ctx.UNIQUE_() != nil:
+		constraint = fmt.Sprintf("UNIQUE (%s)", c.indexedColumns(ctx.AllIndexed_column()))
+	case ctx.CHECK_() != nil:
+		constraint = fmt.Sprintf("CHECK (%s)", c.Visit(ctx.Expr()))
+	case ctx.FOREIGN_() != nil:
+		var columns []string
+		for _, column := range ctx.AllColumn_name() {
+			columns = append(columns, c.name(column))
+		}
+		constraint = fmt.Sprintf("FOREIGN KEY (%s) %s", strings.Join(columns, ", "), c.Visit(ctx.Foreign_key_clause()))
+	default:
+		return c.unsupported(ctx)
+	}
+
+	c.conflictClause(ctx.Conflict_clause())
+
+	if ctx.CONSTRAINT_() != nil {
+		return fmt.Sprintf("CONSTRAINT %s %s", c.name(ctx.Name()), constraint)
+	}
+	return constraint
+}
+
+// indexedColumns renders the column list of a key constraint. Collations and
+// sort orders in the list only affect the index SQLite builds for the key.
+func (c *translatorCore) indexedColumns(columns []parser.IIndexed_columnContext) string {
+	var names []string
+	for _, column := range columns {
+		if column.Column_name() != nil {
+			names = append(names, c.name(column.Column_name()))
+		} else {
+			names = append(names, c.unsupported(column))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func (c *translatorCore) VisitForeign_key_clause(ctx *parser.Foreign_key_clauseContext) any {
+	reference := fmt.Sprintf("REFERENCES %s", c.name(ctx.Foreign_table()))
+	if columns := ctx.AllColumn_name(); len(columns) > 0 {
+		var names []string
+		for _, column := range columns {
+			names = append(names, c.name(column))
+		}
+		reference = fmt.Sprintf("%s (%s)", reference, strings.Join(names, ", "))
+	}
+
+	// DuckDB only enforces NO ACTION and RESTRICT, both of which are what
+	// it does without the clause, so every action is dropped.
+	for _, action := range foreignKeyActions(ctx) {
+		if !strings.HasSuffix(action, "NO ACTION") && !strings.HasSuffix(action, "RESTRICT") {
+			c.warnf(ctx, "%s dropped, DuckDB does not support foreign key actions", action)
+		}
+	}
+	if len(ctx.AllMATCH_()) > 0 {
+		c.warnf(ctx, "MATCH dropped, DuckDB does not support foreign key match types")
+	}
+	if ctx.DEFERRABLE_() != nil && ctx.NOT_() == nil {
+		c.warnf(ctx, "DEFERRABLE dropped, DuckDB checks foreign keys immediately")
+	}
+
+	return reference
+}
+
+// foreignKeyActions returns the ON DELETE and ON UPDATE clauses of ctx as
+// upper-case text, e.g. "ON UPDATE SET NULL".
+func foreignKeyActions(ctx *parser.Foreign_key_clauseContext) []string {
+	var actions []string
+	var words []string
+	flush := func() {
+		if len(words) > 0 {
+			actions = append(actions, strings.Join(words, " "))
+			words = nil
+		}
+	}
+
+	for _, child := range ctx.GetChildren() {
+		node, ok := child.(antlr.TerminalNode)
+		if !ok {
+			flush()
+			continue
+		}
+		switch node.GetSymbol().GetTokenType() {
+		case parser.SQLiteParserON_:
+			flush()
+			words = append(words, "ON")
+		case parser.SQLiteParserMATCH_, parser.SQLiteParserNOT_, parser.SQLiteParserDEFERRABLE_:
+			flush()
+		default:
+			if len(words) > 0 {
+				words = append(words, strings.ToUpper(node.GetText()))
+			}
+		}
+	}
+	flush()
+	return actions
+}
+
+// conflictClause drops a constraint conflict clause. ABORT is what SQLite
+// does without one, every other resolution has no DuckDB equivalent.
+func (c *translatorCore) conflictClause(ctx parser.IConflict_clauseContext) {
+	if ctx == nil || ctx.ABORT_() != nil {
+		return
+	}
+	c.warnf(ctx, "%s dropped, DuckDB has no constraint conflict resolution", strings.ToUpper(c.sourceText(ctx)))
+}
+
+// collation maps a SQLite collation to DuckDB. ok is false when the
+// collation should be dropped.
+func (c *translatorCore) collation(ctx parser.ICollation_nameContext) (string, bool) {
+	name := c.name(ctx)
+	switch strings.ToUpper(unquoteIdentifier(name)) {
+	case "BINARY":
+		// BINARY is the default in both databases
+		return "", false
+	case "NOCASE":
+		return "NOCASE", true
+	case "RTRIM":
+		c.warnf(ctx, "COLLATE RTRIM dropped, DuckDB has no such collation")
+		return "", false
+	}
+	return name, true
+}
+
+// columnType maps a declared column type to DuckDB. A nil typeName is a
+// column declared without a type.
+func (c *translatorCore) columnType(typeName parser.IType_nameContext) string {
+	if typeName == nil {
+		return c.typeMap().duckDBType("")
+	}
+
+	var words, args []string
+	for _, name := range typeName.AllName() {
+		words = append(words, name.GetText())
+	}
+	for _, number := range typeName.AllSigned_number() {
+		args = append(args, number.GetText())
+	}
+
+	return c.typeMap().duckDBType(strings.Join(words, " "), args...)
+}
+
+func (c *translatorCore) typeMap() TypeMap {
+	if c.types == nil {
+		return defaultTypeMap
+	}
+	return *c.types
+}
@@ -0,0 +1,282 @@
+package translator
+
+import (
+	"strings"
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestCreateTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "integer text and real affinity",
+			input:    "CREATE TABLE users (id INTEGER, name TEXT, score REAL)",
+			expected: "CREATE TABLE users (id BIGINT, name VARCHAR, score DOUBLE)",
+		},
+		{
+			name:     "sized character types",
+			input:    "CREATE TABLE users (name VARCHAR(20), code NCHAR(2), bio CLOB)",
+			expected: "CREATE TABLE users (name VARCHAR, code VARCHAR, bio VARCHAR)",
+		},
+		{
+			name:     "multi word integer type",
+			input:    "CREATE TABLE counters (hits UNSIGNED BIG INT, small TINYINT)",
+			expected: "CREATE TABLE counters (hits BIGINT, small BIGINT)",
+		},
+		{
+			name:     "float and double",
+			input:    "CREATE TABLE m (a FLOAT, b DOUBLE PRECISION, c DOUBLE)",
+			expected: "CREATE TABLE m (a DOUBLE, b DOUBLE, c DOUBLE)",
+		},
+		{
+			name:     "numeric affinity keeps precision",
+			input:    "CREATE TABLE prices (amount DECIMAL(10, 2), ratio NUMERIC, total NUMERIC(12))",
+			expected: "CREATE TABLE prices (amount DECIMAL(10, 2), ratio DOUBLE, total DECIMAL(12))",
+		},
+		{
+			name:     "blob and untyped columns",
+			input:    "CREATE TABLE files (data BLOB, anything)",
+			expected: "CREATE TABLE files (data BLOB, anything VARCHAR)",
+		},
+		{
+			name:     "named types",
+			input:    "CREATE TABLE events (active BOOLEAN, day DATE, at DATETIME)",
+			expected: "CREATE TABLE events (active BOOLEAN, day DATE, at TIMESTAMP)",
+		},
+		{
+			name:     "temporary table if not exists",
+			input:    "CREATE TEMP TABLE IF NOT EXISTS main.scratch (v INT)",
+			expected: "CREATE TEMPORARY TABLE IF NOT EXISTS main.scratch (v BIGINT)",
+		},
+		{
+			name:     "create table as select",
+			input:    "CREATE TABLE adults AS SELECT * FROM users WHERE age >= 18",
+			expected: "CREATE TABLE adults AS SELECT * FROM users WHERE age >= 18",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics: %v", core.diagnostics)
+			}
+		})
+	}
+}
+
+func TestCreateTableCustomTypeMap(t *testing.T) {
+	types := DefaultTypeMap()
+	types.Integer = "INTEGER"
+	types.Untyped = "JSON"
+	types.Names["MONEY"] = "DECIMAL(18, 4)"
+
+	core := translatorCore{
+		BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+		types:                   &types,
+	}
+
+	tree := createParseTree(t, &core, "CREATE TABLE accounts (id INT, balance money, extra)")
+	got := core.Visit(tree).(string)
+
+	expected := "CREATE TABLE accounts (id INTEGER, balance DECIMAL(18, 4), extra JSON)"
+	if got != expected {
+		t.Errorf("got %q, want %q", got, expected)
+	}
+}
+
+func TestRowidAlias(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "integer primary key autoincrement",
+			input:    "CREATE TABLE users (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+			expected: "CREATE SEQUENCE users_id_seq;\nCREATE TABLE users (id BIGINT PRIMARY KEY DEFAULT nextval('users_id_seq'), name VARCHAR)",
+			warnings: 1,
+		},
+		{
+			name:     "integer primary key without autoincrement",
+			input:    "CREATE TABLE IF NOT EXISTS main.tags (tag_id integer primary key, label TEXT)",
+			expected: "CREATE SEQUENCE IF NOT EXISTS main.tags_tag_id_seq;\nCREATE TABLE IF NOT EXISTS main.tags (tag_id BIGINT PRIMARY KEY DEFAULT nextval('main.tags_tag_id_seq'), label VARCHAR)",
+			warnings: 1,
+		},
+		{
+			name:     "temporary table",
+			input:    "CREATE TEMP TABLE scratch (id INTEGER PRIMARY KEY)",
+			expected: "CREATE TEMPORARY SEQUENCE scratch_id_seq;\nCREATE TEMPORARY TABLE scratch (id BIGINT PRIMARY KEY DEFAULT nextval('scratch_id_seq'))",
+			warnings: 1,
+		},
+		{
+			name:     "int primary key is not a rowid alias",
+			input:    "CREATE TABLE t (id INT PRIMARY KEY)",
+			expected: "CREATE TABLE t (id BIGINT PRIMARY KEY)",
+		},
+		{
+			name:     "descending primary key is not a rowid alias",
+			input:    "CREATE TABLE t (id INTEGER PRIMARY KEY DESC)",
+			expected: "CREATE TABLE t (id BIGINT PRIMARY KEY)",
+		},
+		{
+			name:  "rowid references use the key column",
+			input: "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT); SELECT rowid, name FROM users WHERE oid > 10 ORDER BY rowid",
+			expected: "CREATE SEQUENCE users_id_seq;\nCREATE TABLE users (id BIGINT PRIMARY KEY DEFAULT nextval('users_id_seq'), name VARCHAR);\n" +
+				"SELECT id, name FROM users WHERE id > 10 ORDER BY id",
+			warnings: 1,
+		},
+		{
+			name:  "qualified rowid references resolve aliases",
+			input: "CREATE TABLE users (id INTEGER PRIMARY KEY); SELECT u._rowid_, o.rowid FROM users u JOIN orders o ON u.rowid = o.user_id",
+			expected: "CREATE SEQUENCE users_id_seq;\nCREATE TABLE users (id BIGINT PRIMARY KEY DEFAULT nextval('users_id_seq'));\n" +
+				"SELECT u.id, o.rowid FROM users AS u JOIN orders AS o ON u.id = o.user_id",
+			warnings: 1,
+		},
+		{
+			name:  "compound order by a rowid result column",
+			input: "CREATE TABLE t (id INTEGER PRIMARY KEY); SELECT rowid FROM t UNION SELECT rowid FROM t ORDER BY rowid DESC",
+			expected: "CREATE SEQUENCE t_id_seq;\nCREATE TABLE t (id BIGINT PRIMARY KEY DEFAULT nextval('t_id_seq'));\n" +
+				"SELECT id FROM t UNION SELECT id FROM t ORDER BY 1 DESC",
+			warnings: 1,
+		},
+		{
+			name:     "unknown tables use the rowid pseudo column",
+			input:    "SELECT oid, _rowid_ FROM events ORDER BY rowid",
+			expected: "SELECT rowid, rowid FROM events ORDER BY rowid",
+		},
+		{
+			name:     "declared rowid column is left alone",
+			input:    "CREATE TABLE legacy (oid TEXT); SELECT oid FROM legacy",
+			expected: "CREATE TABLE legacy (oid VARCHAR);\nSELECT oid FROM legacy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings []string
+	}{
+		{
+			name:     "column constraints",
+			input:    "CREATE TABLE users (email TEXT NOT NULL UNIQUE, age INT CHECK (age >= 0), nick TEXT NULL)",
+			expected: "CREATE TABLE users (email VARCHAR NOT NULL UNIQUE, age BIGINT CHECK (age >= 0), nick VARCHAR)",
+		},
+		{
+			name:     "defaults",
+			input:    "CREATE TABLE t (a INT DEFAULT -1, b TEXT DEFAULT 'x', c TIMESTAMP DEFAULT CURRENT_TIMESTAMP, d INT DEFAULT (1 + 2))",
+			expected: "CREATE TABLE t (a BIGINT DEFAULT -1, b VARCHAR DEFAULT 'x', c TIMESTAMP DEFAULT CURRENT_TIMESTAMP, d BIGINT DEFAULT (1 + 2))",
+		},
+		{
+			name:     "collations",
+			input:    "CREATE TABLE t (a TEXT COLLATE NOCASE, b TEXT COLLATE BINARY, c TEXT COLLATE RTRIM)",
+			expected: "CREATE TABLE t (a VARCHAR COLLATE NOCASE, b VARCHAR, c VARCHAR)",
+			warnings: []string{"COLLATE RTRIM dropped, DuckDB has no such collation"},
+		},
+		{
+			name:     "named constraints",
+			input:    "CREATE TABLE t (a INT CONSTRAINT positive CHECK (a > 0), CONSTRAINT t_pk PRIMARY KEY (a))",
+			expected: "CREATE TABLE t (a BIGINT CONSTRAINT positive CHECK (a > 0), CONSTRAINT t_pk PRIMARY KEY (a))",
+		},
+		{
+			name:     "table constraints",
+			input:    "CREATE TABLE t (a INT, b TEXT, PRIMARY KEY (a, b DESC), UNIQUE (b COLLATE NOCASE), CHECK (a <> 0))",
+			expected: "CREATE TABLE t (a BIGINT, b VARCHAR, PRIMARY KEY (a, b), UNIQUE (b), CHECK (a <> 0))",
+		},
+		{
+			name:     "foreign keys",
+			input:    "CREATE TABLE orders (user_id INT REFERENCES users (id) ON DELETE NO ACTION, shop_id INT, FOREIGN KEY (shop_id) REFERENCES shops)",
+			expected: "CREATE TABLE orders (user_id BIGINT REFERENCES users (id), shop_id BIGINT, FOREIGN KEY (shop_id) REFERENCES shops)",
+		},
+		{
+			name:     "foreign key actions and deferral",
+			input:    "CREATE TABLE orders (user_id INT REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL DEFERRABLE INITIALLY DEFERRED)",
+			expected: "CREATE TABLE orders (user_id BIGINT REFERENCES users (id))",
+			warnings: []string{
+				"ON UPDATE CASCADE dropped, DuckDB does not support foreign key actions",
+				"ON DELETE SET NULL dropped, DuckDB does not support foreign key actions",
+				"DEFERRABLE dropped, DuckDB checks foreign keys immediately",
+			},
+		},
+		{
+			name:     "conflict clauses",
+			input:    "CREATE TABLE t (a INT UNIQUE ON CONFLICT REPLACE, b INT NOT NULL ON CONFLICT ABORT)",
+			expected: "CREATE TABLE t (a BIGINT UNIQUE, b BIGINT NOT NULL)",
+			warnings: []string{"ON CONFLICT REPLACE dropped, DuckDB has no constraint conflict resolution"},
+		},
+		{
+			name:     "generated columns",
+			input:    "CREATE TABLE t (a INT, b INT GENERATED ALWAYS AS (a * 2) VIRTUAL, c INT AS (a + 1) STORED)",
+			expected: "CREATE TABLE t (a BIGINT, b BIGINT GENERATED ALWAYS AS (a * 2), c BIGINT GENERATED ALWAYS AS (a + 1))",
+			warnings: []string{"STORED generated column made VIRTUAL, DuckDB only supports virtual generated columns"},
+		},
+		{
+			name:     "without rowid",
+			input:    "CREATE TABLE kv (k INTEGER PRIMARY KEY, v TEXT) WITHOUT ROWID",
+			expected: "CREATE TABLE kv (k BIGINT PRIMARY KEY, v VARCHAR)",
+			warnings: []string{"WITHOUT ROWID dropped, DuckDB tables have no rowid storage choice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+
+			var warnings []string
+			for _, diag := range core.diagnostics {
+				if diag.Severity != SeverityWarning {
+					t.Errorf("unexpected error: %v", diag)
+				}
+				warnings = append(warnings, diag.Msg)
+			}
+			if strings.Join(warnings, "\n") != strings.Join(tt.warnings, "\n") {
+				t.Errorf("got warnings %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
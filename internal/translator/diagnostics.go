@@ -0,0 +1,150 @@
+package translator
+
+import (
+	"fmt"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// Mode controls what happens when the translator meets a construct it has
+// no translation for.
+type Mode int
+
+const (
+	// Strict reports unhandled constructs as errors.
+	Strict Mode = iota
+	// Lenient copies unhandled constructs from the source text unchanged
+	// and reports them as warnings.
+	Lenient
+)
+
+// Severity separates errors, which make Translate fail, from warnings.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// Position is a 1-based line and 0-based column in the input, matching the
+// positions reported by ANTLR.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Diagnostic is a problem found while translating a parsed statement.
+type Diagnostic struct {
+	Severity Severity
+	// Rule is the grammar rule the diagnostic refers to, e.g. "expr".
+	Rule string
+	// Start and Stop are the positions of the first and last token of the
+	// construct.
+	Start Position
+	Stop  Position
+	Msg   string
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("line %d:%d: %s", d.Start.Line, d.Start.Column, d.Msg)
+}
+
+// Diagnostics is returned by Translate when translation reported errors.
+type Diagnostics []*Diagnostic
+
+func (d Diagnostics) Error() string {
+	switch len(d) {
+	case 0:
+		return "no diagnostics"
+	case 1:
+		return d[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", d[0], len(d)-1)
+}
+
+func (d Diagnostics) errors() Diagnostics {
+	var errs Diagnostics
+	for _, diag := range d {
+		if diag.Severity == SeverityError {
+			errs = append(errs, diag)
+		}
+	}
+	return errs
+}
+
+func (c *translatorCore) report(severity Severity, ctx antlr.ParserRuleContext, format string, args ...any) {
+	diag := &Diagnostic{
+		Severity: severity,
+		Rule:     ruleName(ctx),
+		Msg:      fmt.Sprintf(format, args...),
+	}
+	if start := ctx.GetStart(); start != nil {
+		diag.Start = Position{Line: start.GetLine(), Column: start.GetColumn()}
+	}
+	if stop := ctx.GetStop(); stop != nil {
+		diag.Stop = Position{Line: stop.GetLine(), Column: stop.GetColumn()}
+	}
+	c.diagnostics = append(c.diagnostics, diag)
+}
+
+func (c *translatorCore) errorf(ctx antlr.ParserRuleContext, format string, args ...any) {
+	c.report(SeverityError, ctx, format, args...)
+}
+
+func (c *translatorCore) warnf(ctx antlr.ParserRuleContext, format string, args ...any) {
+	c.report(SeverityWarning, ctx, format, args...)
+}
+
+// unsupported reports ctx as a construct without a translation and returns
+// its original source text, which is what lenient mode emits for it.
+func (c *translatorCore) unsupported(ctx antlr.ParserRuleContext) string {
+	text := c.sourceText(ctx)
+	if c.mode == Lenient {
+		c.warnf(ctx, "unsupported %s copied unchanged: %s", ruleName(ctx), text)
+	} else {
+		c.errorf(ctx, "unsupported %s: %s", ruleName(ctx), text)
+	}
+	return text
+}
+
+// unsupportedf reports a part of ctx that cannot be expressed in DuckDB and
+// is left out of the translation. It is an error in strict mode and a
+// warning in lenient mode.
+func (c *translatorCore) unsupportedf(ctx antlr.ParserRuleContext, format string, args ...any) {
+	if c.mode == Lenient {
+		c.warnf(ctx, format, args...)
+	} else {
+		c.errorf(ctx, format, args...)
+	}
+}
+
+// sourceText returns the input covered by ctx including whitespace and
+// comments, unlike GetText which concatenates the default channel tokens.
+func (c *translatorCore) sourceText(ctx antlr.ParserRuleContext) string {
+	start, stop := ctx.GetStart(), ctx.GetStop()
+	if start == nil || stop == nil || stop.GetTokenIndex() < start.GetTokenIndex() {
+		return ""
+	}
+
+	if c.tokens != nil {
+		return c.tokens.GetTextFromTokens(start, stop)
+	}
+	return start.GetInputStream().GetTextFromInterval(antlr.NewInterval(start.GetStart(), stop.GetStop()))
+}
+
+func ruleName(ctx antlr.ParserRuleContext) string {
+	ruleNames := parser.SQLiteParserParserStaticData.RuleNames
+	if i := ctx.GetRuleIndex(); i >= 0 && i < len(ruleNames) {
+		return ruleNames[i]
+	}
+	return "unknown rule"
+}
@@ -0,0 +1,369 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+func (c *translatorCore) VisitInsert_stmt(ctx *parser.Insert_stmtContext) any {
+	query := fmt.Sprintf("%s INTO %s", c.insertVerb(ctx), c.qualifiedName(ctx.Schema_name(), ctx.Table_name()))
+
+	if alias := ctx.Table_alias(); alias != nil {
+		query = fmt.Sprintf("%s AS %s", query, c.name(alias))
+	}
+
+	if columns := ctx.AllColumn_name(); len(columns) > 0 {
+		var names []string
+		for _, column := range columns {
+			names = append(names, c.name(column))
+		}
+		query = fmt.Sprintf("%s (%s)", query, strings.Join(names, ", "))
+	}
+
+	switch {
+	case ctx.Values_clause() != nil:
+		query = fmt.Sprintf("%s %s", query, c.Visit(ctx.Values_clause()))
+	case ctx.Select_stmt() != nil:
+		query = fmt.Sprintf("%s %s", query, c.Visit(ctx.Select_stmt()))
+	default:
+		query = fmt.Sprintf("%s DEFAULT VALUES", query)
+	}
+
+	// Unqualified columns in DO UPDATE and RETURNING refer to the target table
+	c.pushScope([]scopeTable{insertTarget(ctx)})
+	defer c.popScope()
+
+	if upsert := ctx.Upsert_clause(); upsert != nil {
+		query = fmt.Sprintf("%s %s", query, c.Visit(upsert))
+	}
+
+	return c.withReturning(c.withCommonTables(ctx.With_clause(), query), ctx.Returning_clause())
+}
+
+func insertTarget(ctx *parser.Insert_stmtContext) scopeTable {
+	table := scopeTable{name: strings.ToLower(unquoteIdentifier(ctx.Table_name().GetText()))}
+	table.alias = table.name
+	if alias := ctx.Table_alias(); alias != nil {
+		table.alias = strings.ToLower(unquoteIdentifier(alias.GetText()))
+	}
+	return table
+}
+
+func (c *translatorCore) VisitUpsert_clause(ctx *parser.Upsert_clauseContext) any {
+	clause := "ON CONFLICT"
+	if columns := ctx.AllIndexed_column(); len(columns) > 0 {
+		clause = fmt.Sprintf("%s (%s)", clause, c.indexedColumns(columns))
+	}
+
+	// A WHERE before DO selects a partial index as the conflict target.
+	// DuckDB has no partial indexes, so its unique index covers every row.
+	doToken := ctx.DO_().GetSymbol().GetTokenIndex()
+	var updateWhere antlr.Tree
+	for _, where := range ctx.AllWHERE_() {
+		if where.GetSymbol().GetTokenIndex() < doToken {
+			c.warnf(ctx, "conflict target WHERE dropped, DuckDB has no partial indexes")
+		} else {
+			updateWhere = childAfter(ctx, where)
+		}
+	}
+
+	if ctx.NOTHING_() != nil {
+		return fmt.Sprintf("%s DO NOTHING", clause)
+	}
+
+	clause = fmt.Sprintf("%s DO UPDATE SET %s", clause, c.assignments(ctx, ctx.SET_()))
+	if updateWhere != nil {
+		clause = fmt.Sprintf("%s WHERE %s", clause, c.Visit(updateWhere.(antlr.ParseTree)))
+	}
+	return clause
+}
+
+// assignments renders the `column = expr` list that follows set in an
+// UPDATE or upsert, up to the next clause keyword.
+func (c *translatorCore) assignments(ctx antlr.ParserRuleContext, set antlr.TerminalNode) string {
+	var result, targets []string
+	started := false
+	for _, child := range ctx.GetChildren() {
+		if !started {
+			started = child == set
+			continue
+		}
+
+		switch child := child.(type) {
+		case *parser.Column_nameContext:
+			targets = []string{c.name(child)}
+		case *parser.Column_name_listContext:
+			targets = nil
+			for _, column := range child.AllColumn_name() {
+				targets = append(targets, c.name(column))
+			}
+		case *parser.ExprContext:
+			result = append(result, c.assignment(targets, child)...)
+		case antlr.TerminalNode:
+			if tokenType := child.GetSymbol().GetTokenType(); tokenType != parser.SQLiteParserCOMMA && tokenType != parser.SQLiteParserASSIGN {
+				return strings.Join(result, ", ")
+			}
+		}
+	}
+	return strings.Join(result, ", ")
+}
+
+// assignment translates one assignment. DuckDB cannot assign a row value to
+// a column list, so `(a, b) = (1, 2)` is split into `a = 1, b = 2`.
+func (c *translatorCore) assignment(targets []string, value *parser.ExprContext) []string {
+	if len(targets) == 1 {
+		return []string{fmt.Sprintf("%s = %s", targets[0], c.Visit(value))}
+	}
+
+	values := rowValue(value)
+	if len(values) != len(targets) {
+		c.unsupportedf(value, "assignment of %s to a column list has no DuckDB equivalent", c.sourceText(value))
+		return []string{fmt.Sprintf("(%s) = %s", strings.Join(targets, ", "), c.Visit(value))}
+	}
+
+	var result []string
+	for i, target := range targets {
+		result = append(result, fmt.Sprintf("%s = %s", target, c.Visit(values[i])))
+	}
+	return result
+}
+
+// rowValue returns the elements of a parenthesised expression list, or nil
+// if expr is something else.
+func rowValue(expr *parser.ExprContext) []parser.IExprContext {
+	if expr.OPEN_PAR() == nil || expr.GetChild(0) != expr.OPEN_PAR() || expr.Select_stmt() != nil {
+		return nil
+	}
+	return expr.AllExpr()
+}
+
+// childAfter returns the child of ctx that follows node.
+func childAfter(ctx antlr.ParserRuleContext, node antlr.Tree) antlr.Tree {
+	children := ctx.GetChildren()
+	for i, child := range children {
+		if child == node && i+1 < len(children) {
+			return children[i+1]
+		}
+	}
+	return nil
+}
+
+// insertVerb translates the INSERT keyword and its conflict resolution.
+// DuckDB knows OR REPLACE and OR IGNORE, the other resolutions control how
+// much of a failed statement SQLite rolls back and have no equivalent.
+func (c *translatorCore) insertVerb(ctx *parser.Insert_stmtContext) string {
+	switch {
+	case ctx.REPLACE_() != nil:
+		return "INSERT OR REPLACE"
+	case ctx.IGNORE_() != nil:
+		return "INSERT OR IGNORE"
+	case ctx.ABORT_() != nil:
+		// ABORT is also what happens without a conflict resolution
+		c.warnf(ctx, "INSERT OR ABORT translated to INSERT, aborting is the default")
+	case ctx.ROLLBACK_() != nil:
+		c.unsupportedf(ctx, "INSERT OR ROLLBACK has no DuckDB equivalent, translated to INSERT")
+	case ctx.FAIL_() != nil:
+		c.unsupportedf(ctx, "INSERT OR FAIL has no DuckDB equivalent, translated to INSERT")
+	}
+	return "INSERT"
+}
+
+func (c *translatorCore) VisitUpdate_stmt(ctx *parser.Update_stmtContext) any {
+	target := ctx.Qualified_table_name()
+	query := fmt.Sprintf("%s %s", c.updateVerb(ctx), c.Visit(target))
+
+	// SET and WHERE see the tables of the FROM clause next to the target
+	scope := []scopeTable{qualifiedTarget(target)}
+	if ctx.FROM_() != nil {
+		scope = append(scope, tablesScope(ctx.AllTable_or_subquery(), ctx.Join_clause())...)
+	}
+	c.pushScope(scope)
+	defer c.popScope()
+
+	query = fmt.Sprintf("%s SET %s", query, c.assignments(ctx, ctx.SET_()))
+
+	if ctx.FROM_() != nil {
+		query = fmt.Sprintf("%s FROM %s", query, c.tableList(ctx.AllTable_or_subquery(), ctx.Join_clause()))
+	}
+
+	if where := ctx.WHERE_(); where != nil {
+		query = fmt.Sprintf("%s WHERE %s", query, c.Visit(childAfter(ctx, where).(antlr.ParseTree)))
+	}
+
+	return c.withReturning(c.withCommonTables(ctx.With_clause(), query), ctx.Returning_clause())
+}
+
+func (c *translatorCore) VisitUpdate_stmt_limited(ctx *parser.Update_stmt_limitedContext) any {
+	target := ctx.Qualified_table_name()
+	query := fmt.Sprintf("%s %s", c.updateVerb(ctx), c.Visit(target))
+
+	c.pushScope([]scopeTable{qualifiedTarget(target)})
+	defer c.popScope()
+
+	query = fmt.Sprintf("%s SET %s", query, c.assignments(ctx, ctx.SET_()))
+
+	var where parser.IExprContext
+	if ctx.WHERE_() != nil {
+		where = childAfter(ctx, ctx.WHERE_()).(parser.IExprContext)
+	}
+	if clause := c.limitedWhere(target, where, ctx.Order_by_stmt(), ctx.Limit_stmt()); clause != "" {
+		query = fmt.Sprintf("%s %s", query, clause)
+	}
+
+	return c.withReturning(c.withCommonTables(ctx.With_clause(), query), ctx.Returning_clause())
+}
+
+func (c *translatorCore) VisitDelete_stmt(ctx *parser.Delete_stmtContext) any {
+	target := ctx.Qualified_table_name()
+	query := fmt.Sprintf("DELETE FROM %s", c.Visit(target))
+
+	c.pushScope([]scopeTable{qualifiedTarget(target)})
+	defer c.popScope()
+
+	if where := ctx.Expr(); where != nil {
+		query = fmt.Sprintf("%s WHERE %s", query, c.Visit(where))
+	}
+
+	return c.withReturning(c.withCommonTables(ctx.With_clause(), query), ctx.Returning_clause())
+}
+
+func (c *translatorCore) VisitDelete_stmt_limited(ctx *parser.Delete_stmt_limitedContext) any {
+	target := ctx.Qualified_table_name()
+	query := fmt.Sprintf("DELETE FROM %s", c.Visit(target))
+
+	c.pushScope([]scopeTable{qualifiedTarget(target)})
+	defer c.popScope()
+
+	if clause := c.limitedWhere(target, ctx.Expr(), ctx.Order_by_stmt(), ctx.Limit_stmt()); clause != "" {
+		query = fmt.Sprintf("%s %s", query, clause)
+	}
+
+	return c.withReturning(c.withCommonTables(ctx.With_clause(), query), ctx.Returning_clause())
+}
+
+// withCommonTables prefixes a translated statement with its WITH clause.
+func (c *translatorCore) withCommonTables(with parser.IWith_clauseContext, query string) string {
+	if with == nil {
+		return query
+	}
+	return fmt.Sprintf("%s %s", c.Visit(with), query)
+}
+
+// withReturning appends the RETURNING clause, if any, to a translated
+// statement. SQLite accepts it before ORDER BY and LIMIT, DuckDB only at the
+// end, which the rowid rewrite takes care of.
+func (c *translatorCore) withReturning(query string, returning parser.IReturning_clauseContext) string {
+	if returning == nil {
+		return query
+	}
+	return fmt.Sprintf("%s %s", query, c.Visit(returning))
+}
+
+func (c *translatorCore) VisitReturning_clause(ctx *parser.Returning_clauseContext) any {
+	var columns []string
+	for _, column := range ctx.AllResult_column() {
+		columns = append(columns, c.visitString(column))
+	}
+	return fmt.Sprintf("RETURNING %s", strings.Join(columns, ", "))
+}
+
+// limitedWhere builds the WHERE clause of an UPDATE or DELETE that may carry
+// ORDER BY and LIMIT. DuckDB does not accept them there, so the rows are
+// picked by a subquery on the target's rowid instead:
+//
+//	DELETE FROM t WHERE rowid IN (SELECT rowid FROM t WHERE ... ORDER BY ... LIMIT n)
+func (c *translatorCore) limitedWhere(target parser.IQualified_table_nameContext, where parser.IExprContext, orderBy parser.IOrder_by_stmtContext, limit parser.ILimit_stmtContext) string {
+	if limit == nil {
+		if where == nil {
+			return ""
+		}
+		return fmt.Sprintf("WHERE %s", c.Visit(where))
+	}
+
+	rowid, ok := c.rowidColumn("", "rowid")
+	if !ok {
+		// A column named rowid hides the pseudo-column in SQLite as well
+		rowid = "rowid"
+	}
+
+	subquery := fmt.Sprintf("SELECT %s FROM %s", rowid, c.Visit(target))
+	if where != nil {
+		subquery = fmt.Sprintf("%s WHERE %s", subquery, c.Visit(where))
+	}
+	if orderBy != nil {
+		subquery = fmt.Sprintf("%s %s", subquery, c.Visit(orderBy))
+	}
+	subquery = fmt.Sprintf("%s %s", subquery, c.Visit(limit))
+
+	return fmt.Sprintf("WHERE %s IN (%s)", rowid, subquery)
+}
+
+func (c *translatorCore) VisitQualified_table_name(ctx *parser.Qualified_table_nameContext) any {
+	table := c.qualifiedName(ctx.Schema_name(), ctx.Table_name())
+	if ctx.INDEXED_() != nil {
+		c.warnf(ctx, "index hint dropped, DuckDB chooses indexes itself")
+	}
+	if alias := ctx.Alias(); alias != nil {
+		return fmt.Sprintf("%s AS %s", table, c.name(alias))
+	}
+	return table
+}
+
+func qualifiedTarget(ctx parser.IQualified_table_nameContext) scopeTable {
+	table := scopeTable{name: strings.ToLower(unquoteIdentifier(ctx.Table_name().GetText()))}
+	table.alias = table.name
+	if alias := ctx.Alias(); alias != nil {
+		table.alias = strings.ToLower(unquoteIdentifier(alias.GetText()))
+	}
+	return table
+}
+
+// conflictResolution is implemented by the UPDATE statement contexts, both
+// accept an OR clause after the keyword.
+type conflictResolution interface {
+	antlr.ParserRuleContext
+	ROLLBACK_() antlr.TerminalNode
+	ABORT_() antlr.TerminalNode
+	REPLACE_() antlr.TerminalNode
+	FAIL_() antlr.TerminalNode
+	IGNORE_() antlr.TerminalNode
+}
+
+// updateVerb translates the UPDATE keyword and its conflict resolution.
+// DuckDB has no UPDATE OR form at all. OR ABORT is the default and becomes a
+// plain UPDATE, the other resolutions change what a violating row does.
+func (c *translatorCore) updateVerb(ctx conflictResolution) string {
+	switch {
+	case ctx.ABORT_() != nil:
+		c.warnf(ctx, "UPDATE OR ABORT translated to UPDATE, aborting is the default")
+	case ctx.IGNORE_() != nil:
+		c.unsupportedf(ctx, "UPDATE OR IGNORE has no DuckDB equivalent, translated to UPDATE")
+	case ctx.REPLACE_() != nil:
+		c.unsupportedf(ctx, "UPDATE OR REPLACE has no DuckDB equivalent, translated to UPDATE")
+	case ctx.ROLLBACK_() != nil:
+		c.unsupportedf(ctx, "UPDATE OR ROLLBACK has no DuckDB equivalent, translated to UPDATE")
+	case ctx.FAIL_() != nil:
+		c.unsupportedf(ctx, "UPDATE OR FAIL has no DuckDB equivalent, translated to UPDATE")
+	}
+	return "UPDATE"
+}
+
+func (c *translatorCore) VisitValues_clause(ctx *parser.Values_clauseContext) any {
+	var rows []string
+	for _, row := range ctx.AllValue_row() {
+		rows = append(rows, c.visitString(row))
+	}
+	return fmt.Sprintf("VALUES %s", strings.Join(rows, ", "))
+}
+
+func (c *translatorCore) VisitValue_row(ctx *parser.Value_rowContext) any {
+	var values []string
+	for _, expr := range ctx.AllExpr() {
+		values = append(values, c.visitString(expr))
+	}
+	return fmt.Sprintf("(%s)", strings.Join(values, ", "))
+}
@@ -0,0 +1,338 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestInsert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "insert values",
+			input:    "INSERT INTO users (id, name) VALUES (1, 'alice')",
+			expected: "INSERT INTO users (id, name) VALUES (1, 'alice')",
+		},
+		{
+			name:     "insert multiple rows without columns",
+			input:    "INSERT INTO users VALUES (1, 'alice'), (2, 'bob')",
+			expected: "INSERT INTO users VALUES (1, 'alice'), (2, 'bob')",
+		},
+		{
+			name:     "insert select",
+			input:    "INSERT INTO archive (id) SELECT id FROM users WHERE active = 0",
+			expected: "INSERT INTO archive (id) SELECT id FROM users WHERE active = 0",
+		},
+		{
+			name:     "default values",
+			input:    "INSERT INTO main.counters DEFAULT VALUES",
+			expected: "INSERT INTO main.counters DEFAULT VALUES",
+		},
+		{
+			name:     "insert or replace",
+			input:    "INSERT OR REPLACE INTO settings (key, value) VALUES ('theme', 'dark')",
+			expected: "INSERT OR REPLACE INTO settings (key, value) VALUES ('theme', 'dark')",
+		},
+		{
+			name:     "replace into",
+			input:    "REPLACE INTO settings (key, value) VALUES (?, ?)",
+			expected: "INSERT OR REPLACE INTO settings (key, value) VALUES (?, ?)",
+		},
+		{
+			name:     "insert or ignore",
+			input:    "INSERT OR IGNORE INTO tags (name) VALUES (:name)",
+			expected: "INSERT OR IGNORE INTO tags (name) VALUES (:name)",
+		},
+		{
+			name:     "table alias and quoted columns",
+			input:    "INSERT INTO [user data] AS d ([first name]) VALUES ('x')",
+			expected: `INSERT INTO "user data" AS d ("first name") VALUES ('x')`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics: %v", core.diagnostics)
+			}
+		})
+	}
+}
+
+func TestConflictResolution(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		mode     Mode
+		expected string
+		severity Severity
+	}{
+		{
+			name:     "or abort is the default",
+			input:    "INSERT OR ABORT INTO t VALUES (1)",
+			expected: "INSERT INTO t VALUES (1)",
+			severity: SeverityWarning,
+		},
+		{
+			name:     "or rollback in strict mode",
+			input:    "INSERT OR ROLLBACK INTO t VALUES (1)",
+			expected: "INSERT INTO t VALUES (1)",
+			severity: SeverityError,
+		},
+		{
+			name:     "or fail in lenient mode",
+			input:    "INSERT OR FAIL INTO t VALUES (1)",
+			mode:     Lenient,
+			expected: "INSERT INTO t VALUES (1)",
+			severity: SeverityWarning,
+		},
+		{
+			name:     "update or ignore in strict mode",
+			input:    "UPDATE OR IGNORE users SET email = lower(email)",
+			expected: "UPDATE users SET email = lower(email)",
+			severity: SeverityError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+				mode:                    tt.mode,
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != 1 || core.diagnostics[0].Severity != tt.severity {
+				t.Errorf("got diagnostics %v, want one %s", core.diagnostics, tt.severity)
+			}
+		})
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "do nothing",
+			input:    "INSERT INTO tags (name) VALUES ('go') ON CONFLICT DO NOTHING",
+			expected: "INSERT INTO tags (name) VALUES ('go') ON CONFLICT DO NOTHING",
+		},
+		{
+			name:     "do update with excluded",
+			input:    "INSERT INTO counters (key, hits) VALUES ('home', 1) ON CONFLICT (key) DO UPDATE SET hits = hits + excluded.hits",
+			expected: "INSERT INTO counters (key, hits) VALUES ('home', 1) ON CONFLICT (key) DO UPDATE SET hits = hits + excluded.hits",
+		},
+		{
+			name:     "do update with where",
+			input:    "INSERT INTO kv (k, v) VALUES (?, ?) ON CONFLICT (k) DO UPDATE SET v = excluded.v, updated = 1 WHERE excluded.v <> kv.v",
+			expected: "INSERT INTO kv (k, v) VALUES (?, ?) ON CONFLICT (k) DO UPDATE SET v = excluded.v, updated = 1 WHERE excluded.v <> kv.v",
+		},
+		{
+			name:     "column list assignment is split",
+			input:    "INSERT INTO kv (k, a, b) VALUES (1, 2, 3) ON CONFLICT (k) DO UPDATE SET (a, b) = (excluded.a, excluded.b)",
+			expected: "INSERT INTO kv (k, a, b) VALUES (1, 2, 3) ON CONFLICT (k) DO UPDATE SET a = excluded.a, b = excluded.b",
+		},
+		{
+			name:     "conflict target where is dropped",
+			input:    "INSERT INTO users (email) SELECT email FROM staging WHERE true ON CONFLICT (email) WHERE deleted = 0 DO NOTHING",
+			expected: "INSERT INTO users (email) SELECT email FROM staging WHERE true ON CONFLICT (email) DO NOTHING",
+			warnings: 1,
+		},
+		{
+			name:  "rowid in update refers to the target table",
+			input: "CREATE TABLE t (id INTEGER PRIMARY KEY, v TEXT); INSERT INTO t (v) VALUES ('x') ON CONFLICT DO UPDATE SET v = rowid",
+			expected: "CREATE SEQUENCE t_id_seq;\nCREATE TABLE t (id BIGINT PRIMARY KEY DEFAULT nextval('t_id_seq'), v VARCHAR);\n" +
+				"INSERT INTO t (v) VALUES ('x') ON CONFLICT DO UPDATE SET v = id",
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestUpdateDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "update",
+			input:    "UPDATE users SET name = 'x', age = age + 1 WHERE id = 1",
+			expected: "UPDATE users SET name = 'x', age = age + 1 WHERE id = 1",
+		},
+		{
+			name:     "update with alias and column list",
+			input:    "UPDATE main.users AS u SET (name, age) = ('x', 2) WHERE u.id = ?",
+			expected: "UPDATE main.users AS u SET name = 'x', age = 2 WHERE u.id = ?",
+		},
+		{
+			name:     "update from",
+			input:    "UPDATE inventory SET qty = qty - d.n FROM (SELECT item, count(*) AS n FROM sales GROUP BY item) AS d WHERE inventory.item = d.item",
+			expected: "UPDATE inventory SET qty = qty - d.n FROM (SELECT item, count(*) AS n FROM sales GROUP BY item) AS d WHERE inventory.item = d.item",
+		},
+		{
+			name:     "update from join",
+			input:    "UPDATE t SET v = a.v FROM a JOIN b ON a.id = b.id WHERE t.id = b.id",
+			expected: "UPDATE t SET v = a.v FROM a JOIN b ON a.id = b.id WHERE t.id = b.id",
+		},
+		{
+			name:     "update with limit",
+			input:    "UPDATE jobs SET state = 'running' WHERE state = 'queued' ORDER BY created LIMIT 10",
+			expected: "UPDATE jobs SET state = 'running' WHERE rowid IN (SELECT rowid FROM jobs WHERE state = 'queued' ORDER BY created LIMIT 10)",
+		},
+		{
+			name:     "delete",
+			input:    "DELETE FROM sessions WHERE expires < ?",
+			expected: "DELETE FROM sessions WHERE expires < ?",
+		},
+		{
+			name:     "delete everything",
+			input:    "DELETE FROM sessions",
+			expected: "DELETE FROM sessions",
+		},
+		{
+			name:     "delete with index hint",
+			input:    "DELETE FROM sessions INDEXED BY sessions_expires WHERE expires < 0",
+			expected: "DELETE FROM sessions WHERE expires < 0",
+			warnings: 1,
+		},
+		{
+			name:     "delete with order by and limit",
+			input:    "DELETE FROM log AS l WHERE l.level = 'debug' ORDER BY l.ts DESC LIMIT 100",
+			expected: "DELETE FROM log AS l WHERE rowid IN (SELECT rowid FROM log AS l WHERE l.level = 'debug' ORDER BY l.ts DESC LIMIT 100)",
+		},
+		{
+			name:  "limit on a table with a rowid alias",
+			input: "CREATE TABLE q (id INTEGER PRIMARY KEY, v TEXT); DELETE FROM q LIMIT 1",
+			expected: "CREATE SEQUENCE q_id_seq;\nCREATE TABLE q (id BIGINT PRIMARY KEY DEFAULT nextval('q_id_seq'), v VARCHAR);\n" +
+				"DELETE FROM q WHERE id IN (SELECT id FROM q LIMIT 1)",
+			warnings: 1,
+		},
+		{
+			name:  "rowid in where",
+			input: "CREATE TABLE q (id INTEGER PRIMARY KEY, v TEXT); UPDATE q SET v = NULL WHERE rowid = 3",
+			expected: "CREATE SEQUENCE q_id_seq;\nCREATE TABLE q (id BIGINT PRIMARY KEY DEFAULT nextval('q_id_seq'), v VARCHAR);\n" +
+				"UPDATE q SET v = NULL WHERE id = 3",
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestReturning(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "insert returning",
+			input:    "INSERT INTO users (name) VALUES ('a') RETURNING id",
+			expected: "INSERT INTO users (name) VALUES ('a') RETURNING id",
+		},
+		{
+			name:     "upsert returning",
+			input:    "INSERT INTO kv (k, v) VALUES (1, 2) ON CONFLICT (k) DO UPDATE SET v = excluded.v RETURNING *",
+			expected: "INSERT INTO kv (k, v) VALUES (1, 2) ON CONFLICT (k) DO UPDATE SET v = excluded.v RETURNING *",
+		},
+		{
+			name:     "update returning with alias",
+			input:    "UPDATE users SET visits = visits + 1 WHERE id = ? RETURNING visits AS total, users.*",
+			expected: "UPDATE users SET visits = visits + 1 WHERE id = ? RETURNING visits AS total, users.*",
+		},
+		{
+			name:     "delete returning",
+			input:    "DELETE FROM jobs WHERE done RETURNING id, name",
+			expected: "DELETE FROM jobs WHERE done RETURNING id, name",
+		},
+		{
+			name:     "returning moves after the limit rewrite",
+			input:    "DELETE FROM jobs RETURNING id ORDER BY created LIMIT 1",
+			expected: "DELETE FROM jobs WHERE rowid IN (SELECT rowid FROM jobs ORDER BY created LIMIT 1) RETURNING id",
+		},
+		{
+			name:  "returning rowid",
+			input: "CREATE TABLE users (uid INTEGER PRIMARY KEY, name TEXT); INSERT INTO users (name) VALUES ('a') RETURNING rowid",
+			expected: "CREATE SEQUENCE users_uid_seq;\nCREATE TABLE users (uid BIGINT PRIMARY KEY DEFAULT nextval('users_uid_seq'), name VARCHAR);\n" +
+				"INSERT INTO users (name) VALUES ('a') RETURNING uid",
+			warnings: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
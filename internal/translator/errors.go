@@ -0,0 +1,108 @@
+package translator
+
+import (
+	"fmt"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// SyntaxError is a single lexer or parser error found in the input.
+type SyntaxError struct {
+	Line           int
+	Column         int
+	OffendingToken string
+	ExpectedTokens []string
+	Msg            string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// SyntaxErrors is returned by Translate when the input does not parse. It
+// holds every error reported by the lexer and the parser, in input order.
+type SyntaxErrors []*SyntaxError
+
+func (e SyntaxErrors) Error() string {
+	switch len(e) {
+	case 0:
+		return "no syntax errors"
+	case 1:
+		return e[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", e[0], len(e)-1)
+}
+
+// syntaxErrorListener collects errors instead of printing them to stderr
+// like the default console listener does.
+type syntaxErrorListener struct {
+	*antlr.DefaultErrorListener
+	errors SyntaxErrors
+}
+
+func newSyntaxErrorListener() *syntaxErrorListener {
+	return &syntaxErrorListener{
+		DefaultErrorListener: antlr.NewDefaultErrorListener(),
+	}
+}
+
+func (l *syntaxErrorListener) SyntaxError(recognizer antlr.Recognizer, offendingSymbol any, line, column int, msg string, _ antlr.RecognitionException) {
+	err := &SyntaxError{
+		Line:   line,
+		Column: column,
+		Msg:    msg,
+	}
+
+	switch r := recognizer.(type) {
+	case antlr.Parser:
+		if tok, ok := offendingSymbol.(antlr.Token); ok {
+			err.OffendingToken = tok.GetText()
+			if startsExtraUpsert(r, tok) {
+				err.Msg = "more than one ON CONFLICT clause, DuckDB accepts a single ON CONFLICT clause per INSERT"
+			}
+		}
+		err.ExpectedTokens = expectedTokens(r)
+	case *antlr.BaseLexer:
+		// The lexer has no token to report, only the characters it could
+		// not match starting at the current token start.
+		input := r.GetInputStream()
+		err.OffendingToken = input.GetTextFromInterval(antlr.NewInterval(r.TokenStartCharIndex, input.Index()))
+	}
+
+	l.errors = append(l.errors, err)
+}
+
+// startsExtraUpsert reports whether tok is the ON of an ON CONFLICT clause
+// the parser did not expect. SQLite allows several upsert clauses on one
+// INSERT, the grammar only one, matching what DuckDB can express.
+func startsExtraUpsert(p antlr.Parser, tok antlr.Token) bool {
+	stream := p.GetTokenStream()
+	if tok.GetTokenType() != parser.SQLiteParserON_ || stream.Index() != tok.GetTokenIndex() {
+		return false
+	}
+	return stream.LT(2).GetTokenType() == parser.SQLiteParserCONFLICT_
+}
+
+// expectedTokens returns the display names of the tokens the parser would
+// have accepted at its current position.
+func expectedTokens(p antlr.Parser) []string {
+	literalNames := p.GetLiteralNames()
+	symbolicNames := p.GetSymbolicNames()
+
+	var names []string
+	for _, interval := range p.GetExpectedTokens().GetIntervals() {
+		for tokenType := interval.Start; tokenType < interval.Stop; tokenType++ {
+			switch {
+			case tokenType == antlr.TokenEOF:
+				names = append(names, "<EOF>")
+			case tokenType < len(literalNames) && literalNames[tokenType] != "":
+				names = append(names, literalNames[tokenType])
+			case tokenType < len(symbolicNames):
+				names = append(names, symbolicNames[tokenType])
+			}
+		}
+	}
+	return names
+}
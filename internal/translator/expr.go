@@ -0,0 +1,183 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// Expression forms of SQLite that DuckDB spells differently or lacks. The
+// forms both share, such as BETWEEN and CASE, are rebuilt from their parts
+// so their operands are translated.
+
+func init() {
+	registerFunctions(map[string]functionTranslator{
+		// The grammar reads RAISE as a function call more often than not
+		"raise": (*translatorCore).raise,
+	})
+}
+
+func (c *translatorCore) raise(ctx *parser.ExprContext) string {
+	c.unsupportedf(ctx, "RAISE has no DuckDB equivalent, DuckDB has no triggers")
+	return c.sourceText(ctx)
+}
+
+// castExpr translates CAST. SQLite truncates a real cast to an integer and
+// reads the numeric prefix of text, DuckDB rounds and rejects the text.
+func (c *translatorCore) castExpr(ctx *parser.ExprContext) string {
+	operand := ctx.Expr(0)
+	duckType := c.columnType(ctx.Type_name())
+	if affinity(ctx.Type_name().GetText()) != integerAffinity {
+		return fmt.Sprintf("CAST(%s AS %s)", c.visitString(operand), duckType)
+	}
+
+	switch c.numberKind(operand) {
+	case integerNumber:
+	case realNumber:
+		return fmt.Sprintf("CAST(trunc(%s) AS %s)", c.visitString(operand), duckType)
+	default:
+		if text, ok := stringLiteral(operand); !ok || !integerText.MatchString(text) {
+			c.warnf(ctx, "CAST of %s to %s kept as is, DuckDB rounds reals where SQLite truncates them and rejects text such as '12abc' that SQLite reads as 12", c.sourceText(operand), ctx.Type_name().GetText())
+		}
+	}
+	return fmt.Sprintf("CAST(%s AS %s)", c.visitString(operand), duckType)
+}
+
+// integerText matches text both SQLite and DuckDB cast to the same integer.
+var integerText = regexp.MustCompile(`^\s*[+-]?\d+\s*$`)
+
+func (c *translatorCore) caseExpr(ctx *parser.ExprContext) string {
+	var parts []string
+	for _, child := range ctx.GetChildren() {
+		switch child := child.(type) {
+		case *parser.ExprContext:
+			parts = append(parts, c.visitString(child))
+		case antlr.TerminalNode:
+			parts = append(parts, strings.ToUpper(child.GetText()))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func (c *translatorCore) betweenExpr(ctx *parser.ExprContext) string {
+	operator := "BETWEEN"
+	if ctx.NOT_() != nil {
+		operator = "NOT BETWEEN"
+	}
+	return fmt.Sprintf("%s %s %s AND %s", c.operand(ctx.Expr(0), duckDBIn+1), operator, c.operand(ctx.Expr(1), duckDBIn+1), c.operand(ctx.Expr(2), duckDBIn+1))
+}
+
+// nullTest translates the postfix ISNULL, NOTNULL and NOT NULL operators.
+func (c *translatorCore) nullTest(ctx *parser.ExprContext) string {
+	if ctx.ISNULL_() != nil {
+		return fmt.Sprintf("%s IS NULL", c.operand(ctx.Expr(0), duckDBIs+1))
+	}
+	return fmt.Sprintf("%s IS NOT NULL", c.operand(ctx.Expr(0), duckDBIs+1))
+}
+
+// isExpr translates IS and IS NOT, which SQLite allows between any two
+// values as a comparison that treats NULLs as equal. DuckDB only takes NULL,
+// TRUE and FALSE after IS, other operands use IS [NOT] DISTINCT FROM.
+func (c *translatorCore) isExpr(ctx *parser.ExprContext) string {
+	left, right := ctx.Expr(0), ctx.Expr(1)
+	not := ctx.NOT_() != nil
+
+	if ctx.DISTINCT_() != nil {
+		if not {
+			return fmt.Sprintf("%s IS NOT DISTINCT FROM %s", c.operand(left, duckDBIs+1), c.operand(right, duckDBIs+1))
+		}
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", c.operand(left, duckDBIs+1), c.operand(right, duckDBIs+1))
+	}
+
+	// The grammar reads IS NOT x as IS applied to NOT x
+	if operator := right.Unary_operator(); !not && operator != nil && operator.NOT_() != nil {
+		not, right = true, right.Expr(0)
+	}
+
+	if literal := right.Literal_value(); literal != nil && (literal.NULL_() != nil || literal.TRUE_() != nil || literal.FALSE_() != nil) {
+		if not {
+			return fmt.Sprintf("%s IS NOT %s", c.operand(left, duckDBIs+1), strings.ToUpper(literal.GetText()))
+		}
+		return fmt.Sprintf("%s IS %s", c.operand(left, duckDBIs+1), strings.ToUpper(literal.GetText()))
+	}
+
+	if not {
+		return fmt.Sprintf("%s IS DISTINCT FROM %s", c.operand(left, duckDBIs+1), c.operand(right, duckDBIs+1))
+	}
+	return fmt.Sprintf("%s IS NOT DISTINCT FROM %s", c.operand(left, duckDBIs+1), c.operand(right, duckDBIs+1))
+}
+
+// inExpr translates IN and NOT IN. SQLite also accepts a table or a table
+// function as the right operand, DuckDB needs a subquery, and an empty list,
+// which DuckDB rejects. It returns the DuckDB precedence of the translation,
+// an empty list becomes a constant.
+func (c *translatorCore) inExpr(ctx *parser.ExprContext) (string, int) {
+	exprs := ctx.AllExpr()
+	value := c.operand(exprs[0], duckDBIn+1)
+	operator := "IN"
+	if ctx.NOT_() != nil {
+		operator = "NOT IN"
+	}
+
+	switch {
+	case ctx.Select_stmt() != nil:
+		return fmt.Sprintf("%s %s (%s)", value, operator, c.visitString(ctx.Select_stmt())), duckDBIn
+
+	case ctx.Table_function_name() != nil:
+		return fmt.Sprintf("%s %s (SELECT * FROM %s(%s))", value, operator,
+			c.qualifiedName(ctx.Schema_name(), ctx.Table_function_name()), strings.Join(c.visitAll(exprs[1:]), ", ")), duckDBIn
+
+	case ctx.Table_name() != nil:
+		return fmt.Sprintf("%s %s (SELECT * FROM %s)", value, operator, c.qualifiedName(ctx.Schema_name(), ctx.Table_name())), duckDBIn
+
+	case ctx.OPEN_PAR() != nil:
+		if len(exprs) == 1 {
+			// Never true, not even for a NULL value
+			return fmt.Sprintf("%t", operator == "NOT IN"), duckDBPrimary
+		}
+		return fmt.Sprintf("%s %s (%s)", value, operator, strings.Join(c.visitAll(exprs[1:]), ", ")), duckDBIn
+	}
+
+	// The binary form, where a bare name on the right is a table and a
+	// function call a table function. The grammar also reads a list of a
+	// single name this way.
+	right := exprs[1]
+	if right.Column_name() != nil && right.GetStart().GetTokenType() == parser.SQLiteParserOPEN_PAR {
+		return fmt.Sprintf("%s %s (%s)", value, operator, c.visitString(right)), duckDBIn
+	}
+	if right.Function_name() != nil {
+		return fmt.Sprintf("%s %s (SELECT * FROM %s)", value, operator, c.visitString(right)), duckDBIn
+	}
+	if right.Column_name() != nil {
+		table := right.Column_name()
+		if right.Table_name() != nil {
+			return fmt.Sprintf("%s %s (SELECT * FROM %s.%s)", value, operator, c.name(right.Table_name()), c.name(table)), duckDBIn
+		}
+		return fmt.Sprintf("%s %s (SELECT * FROM %s)", value, operator, c.name(table)), duckDBIn
+	}
+	return fmt.Sprintf("%s %s %s", value, operator, c.operand(right, duckDBIn+1)), duckDBIn
+}
+
+// regexpExpr translates REGEXP, which SQLite leaves to an application
+// defined regexp function, usually one that searches the string. It
+// returns the DuckDB precedence of the translation.
+func (c *translatorCore) regexpExpr(ctx *parser.ExprContext) (string, int) {
+	match := fmt.Sprintf("regexp_matches(%s, %s)", c.visitString(ctx.Expr(0)), c.visitString(ctx.Expr(1)))
+	if ctx.NOT_() != nil {
+		return "NOT " + match, duckDBNot
+	}
+	return match, duckDBPrimary
+}
+
+// visitAll translates each of exprs.
+func (c *translatorCore) visitAll(exprs []parser.IExprContext) []string {
+	var translated []string
+	for _, expr := range exprs {
+		translated = append(translated, c.visitString(expr))
+	}
+	return translated
+}
@@ -0,0 +1,166 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestExpressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "unary operators",
+			input:    "SELECT -a, +b, ~c, NOT d, - -e, -(-f) FROM t",
+			expected: "SELECT -a, +b, ~c, NOT d, - -e, -(-f) FROM t",
+		},
+		{
+			name:     "cast",
+			input:    "SELECT CAST(a AS INTEGER), CAST(b AS varchar(20)), CAST(c AS DECIMAL(10, 2)), CAST( d AS TEXT ) FROM t",
+			expected: "SELECT CAST(a AS BIGINT), CAST(b AS VARCHAR), CAST(c AS DECIMAL(10, 2)), CAST(d AS VARCHAR) FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "cast to integer",
+			input:    "SELECT CAST(3.7 AS INTEGER), CAST(-2.5e0 AS INT), CAST(' 12 ' AS INTEGER), CAST(7 AS BIGINT), CAST('12abc' AS INT) FROM t",
+			expected: "SELECT CAST(trunc(3.7) AS BIGINT), CAST(trunc(-2.5e0) AS BIGINT), CAST(' 12 ' AS BIGINT), CAST(7 AS BIGINT), CAST('12abc' AS BIGINT) FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "cast to numeric",
+			input:    "SELECT CAST(a AS NUMERIC), CAST(b AS NUMERIC(10, 2)), CAST(c AS DECIMAL) FROM t",
+			expected: "SELECT CAST(a AS DOUBLE), CAST(b AS DECIMAL(10, 2)), CAST(c AS DOUBLE) FROM t",
+		},
+		{
+			name:     "case",
+			input:    "SELECT case when a > 0 then 'pos' when a < 0 then 'neg' else 'zero' end, CASE b WHEN 1 THEN 'one' END FROM t",
+			expected: "SELECT CASE WHEN a > 0 THEN 'pos' WHEN a < 0 THEN 'neg' ELSE 'zero' END, CASE b WHEN 1 THEN 'one' END FROM t",
+		},
+		{
+			name:     "between",
+			input:    "SELECT * FROM t WHERE a BETWEEN 1 AND 10 AND b NOT BETWEEN lo AND hi",
+			expected: "SELECT * FROM t WHERE a BETWEEN 1 AND 10 AND b NOT BETWEEN lo AND hi",
+		},
+		{
+			name:     "in lists",
+			input:    "SELECT * FROM t WHERE a IN (1, 2, 3) AND b NOT IN ('x') AND c IN () AND d NOT IN () AND e IN (f)",
+			expected: "SELECT * FROM t WHERE a IN (1, 2, 3) AND b NOT IN ('x') AND false AND true AND e IN (f)",
+		},
+		{
+			name:     "in subquery",
+			input:    "SELECT * FROM t WHERE a NOT IN (SELECT id FROM u)",
+			expected: "SELECT * FROM t WHERE a NOT IN (SELECT id FROM u)",
+		},
+		{
+			name:     "in table",
+			input:    "SELECT * FROM t WHERE a IN banned AND b NOT IN main.allowed",
+			expected: "SELECT * FROM t WHERE a IN (SELECT * FROM banned) AND b NOT IN (SELECT * FROM main.allowed)",
+		},
+		{
+			name:     "in table function",
+			input:    "SELECT * FROM t WHERE a IN json_each(tags)",
+			expected: "SELECT * FROM t WHERE a IN (SELECT * FROM json_each(tags))",
+		},
+		{
+			name:     "null tests",
+			input:    "SELECT a ISNULL, b NOTNULL, c NOT NULL, d IS NULL, e IS NOT NULL FROM t",
+			expected: "SELECT a IS NULL, b IS NOT NULL, c IS NOT NULL, d IS NULL, e IS NOT NULL FROM t",
+		},
+		{
+			name:     "is",
+			input:    "SELECT a IS b, a IS NOT b, a IS TRUE, a IS NOT false, a IS NOT DISTINCT FROM b, a IS DISTINCT FROM b FROM t",
+			expected: "SELECT a IS NOT DISTINCT FROM b, a IS DISTINCT FROM b, a IS TRUE, a IS NOT FALSE, a IS NOT DISTINCT FROM b, a IS DISTINCT FROM b FROM t",
+		},
+		{
+			name:     "regexp",
+			input:    "SELECT * FROM t WHERE a REGEXP '^x' OR b NOT REGEXP 'y$'",
+			expected: "SELECT * FROM t WHERE regexp_matches(a, '^x') OR NOT regexp_matches(b, 'y$')",
+		},
+		{
+			name:     "precedence of postfix forms",
+			input:    "SELECT * FROM t WHERE a OR b NOT REGEXP 'x' AND c IN () OR d NOT REGEXP 'y' AND e",
+			expected: "SELECT * FROM t WHERE a OR NOT regexp_matches(b, 'x') AND false OR NOT regexp_matches(d, 'y') AND e",
+		},
+		{
+			name:     "in, like and between beside equality",
+			input:    "SELECT 2 IN (2) = 1, 'a' LIKE 'a' = 1, 2 BETWEEN 1 AND 3 = 1, x IN (SELECT y FROM u) = 1, a NOT GLOB 'b' <> c FROM t",
+			expected: "SELECT 2 IN (2) = 1, 'a' ILIKE 'a' = 1, 2 BETWEEN 1 AND 3 = 1, x IN (SELECT y FROM u) = 1, a NOT GLOB 'b' <> c FROM t",
+		},
+		{
+			name:     "equality beside in, like and is",
+			input:    "SELECT a = b IN (c, d), a == b LIKE c, a IS NULL = 1, a = 1 IS NULL, a IS b = c FROM t",
+			expected: "SELECT (a = b) IN (c, d), (a == b) ILIKE c, (a IS NULL) = 1, a = 1 IS NULL, (a IS NOT DISTINCT FROM b) = c FROM t",
+		},
+		{
+			name:     "precedence of not",
+			input:    "SELECT * FROM t WHERE NOT a REGEXP 'x' AND NOT b IN ()",
+			expected: "SELECT * FROM t WHERE NOT regexp_matches(a, 'x') AND NOT false",
+		},
+		{
+			name:     "not in a result column",
+			input:    "SELECT NOT d, a IS NOT b, NOT e AS f FROM t",
+			expected: "SELECT NOT d, a IS DISTINCT FROM b, NOT e AS f FROM t",
+		},
+		{
+			name:     "bit operators and comparisons",
+			input:    "SELECT a << 2, b >> 1, c & 3, d | 4, e == 1, f != 2, g <> 3 FROM t",
+			expected: "SELECT a << 2, b >> 1, c & 3, d | 4, e == 1, f != 2, g <> 3 FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{
+			name:    "match",
+			input:   "SELECT * FROM docs WHERE body MATCH 'sqlite'",
+			message: "MATCH has no DuckDB equivalent, it queries full-text search tables",
+		},
+		{
+			name:    "raise",
+			input:   "SELECT RAISE(ABORT, 'no')",
+			message: "RAISE has no DuckDB equivalent, DuckDB has no triggers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			core.Visit(createParseTree(t, &core, tt.input))
+
+			errs := core.diagnostics.errors()
+			if len(errs) != 1 || errs[0].Msg != tt.message {
+				t.Errorf("got diagnostics %v, want error %q", core.diagnostics, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+)
+
+// functionTranslator rewrites a call to a SQLite function whose DuckDB
+// counterpart differs in name, arguments or semantics. call is the whole
+// function call expression, the FILTER and OVER clauses are appended to the
+// result by the caller.
+type functionTranslator func(c *translatorCore, call *parser.ExprContext) string
+
+// functions holds the translators keyed by lower-case SQLite function name.
+// Functions missing from it and from aggregates are emitted unchanged.
+var functions = map[string]functionTranslator{}
+
+func registerFunctions(translators map[string]functionTranslator) {
+	for name, translator := range translators {
+		functions[name] = translator
+	}
+}
+
+func (c *translatorCore) buildFunctionCall(ctx *parser.ExprContext) string {
+	key := strings.ToLower(unquoteIdentifier(ctx.Function_name().GetText()))
+	if translator, ok := functions[key]; ok {
+		return c.callClauses(translator(c, ctx), ctx)
+	}
+
+	name, args, result := c.name(ctx.Function_name()), c.functionArgs(ctx), "%s"
+	if aggregate, ok := aggregates[key]; ok {
+		name, result = aggregate.name, aggregate.result
+		if aggregate.args != nil {
+			args = aggregate.args(args)
+		}
+	}
+
+	var call string
+	switch {
+	case ctx.STAR() != nil:
+		call = fmt.Sprintf("%s(*)", name)
+	case ctx.DISTINCT_() != nil:
+		call = fmt.Sprintf("%s(DISTINCT %s%s)", name, strings.Join(args, ", "), c.callOrder(ctx))
+	default:
+		call = fmt.Sprintf("%s(%s%s)", name, strings.Join(args, ", "), c.callOrder(ctx))
+	}
+	return fmt.Sprintf(result, c.callClauses(call, ctx))
+}
+
+// callClauses appends the FILTER and OVER clauses of a function call.
+func (c *translatorCore) callClauses(call string, ctx *parser.ExprContext) string {
+	if filter := ctx.Filter_clause(); filter != nil {
+		call = fmt.Sprintf("%s %s", call, c.Visit(filter))
+	}
+	if over := ctx.Over_clause(); over != nil {
+		call = fmt.Sprintf("%s %s", call, c.Visit(over))
+	}
+	return call
+}
+
+// functionArgs translates the arguments of a function call.
+func (c *translatorCore) functionArgs(ctx *parser.ExprContext) []string {
+	var args []string
+	for _, expr := range ctx.AllExpr() {
+		args = append(args, c.visitString(expr))
+	}
+	return args
+}
+
+// stringLiteral returns the value of expr if it is a string literal.
+func stringLiteral(expr parser.IExprContext) (string, bool) {
+	literal := expr.Literal_value()
+	if literal == nil || literal.STRING_LITERAL() == nil {
+		return "", false
+	}
+	text := literal.GetText()
+	return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), true
+}
+
+// quoteString renders s as a SQL string literal.
+func quoteString(s string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(s, "'", "''"))
+}
@@ -0,0 +1,158 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"sql-translator/internal/parser"
+)
+
+// The DuckDB json extension has json, json_array, json_object,
+// json_array_length, json_valid, json_group_array and json_group_object
+// with SQLite's semantics, those calls are emitted unchanged.
+
+func init() {
+	registerFunctions(map[string]functionTranslator{
+		"json_extract": (*translatorCore).jsonExtract,
+		"json_set":     (*translatorCore).jsonSet,
+	})
+}
+
+// tableFunctions are the table-valued functions DuckDB provides with the
+// name, arguments and result columns SQLite gives them.
+var tableFunctions = map[string]bool{
+	"json_each": true,
+	"json_tree": true,
+}
+
+// jsonExtract translates json_extract to DuckDB's json_extract_string. Both
+// return JSON strings as text, but json_extract_string returns numbers as
+// text too where SQLite returns integers and reals, see warnJSONText.
+// Several paths give an array of the values found.
+func (c *translatorCore) jsonExtract(call *parser.ExprContext) string {
+	args := c.functionArgs(call)
+	if len(args) > 2 {
+		return fmt.Sprintf("to_json(json_extract(%s, [%s]))", args[0], strings.Join(args[1:], ", "))
+	}
+	c.warnJSONText(call, "json_extract")
+	return fmt.Sprintf("json_extract_string(%s)", strings.Join(args, ", "))
+}
+
+// warnJSONText warns when the text json_extract_string returns for expr is
+// computed with or compared to anything but text literals, where a JSON
+// number gives a different result than it does in SQLite.
+func (c *translatorCore) warnJSONText(expr *parser.ExprContext, function string) {
+	parent, ok := expr.GetParent().(*parser.ExprContext)
+	if !ok {
+		return
+	}
+
+	operands := parent.AllExpr()
+	compared := parent.BETWEEN_() != nil || parent.IN_() != nil
+	if operator := parent.Unary_operator(); operator != nil {
+		compared = operator.NOT_() == nil
+	} else if len(operands) == 2 && parent.GetChild(0) == operands[0] {
+		switch operator, _ := c.binaryOperator(parent); operator {
+		case "+", "-", "*", "/", "%", "&", "|", "<<", ">>":
+			c.warnf(expr, "%s translated to json_extract_string, which returns JSON numbers as text, cast the result to compute with it", function)
+			return
+		case "=", "==", "!=", "<>", "<", "<=", ">", ">=", "IS", "IS NOT":
+			compared = true
+		}
+	}
+	if !compared {
+		return
+	}
+
+	for _, operand := range operands {
+		if _, ok := stringLiteral(operand); !ok && operand != parser.IExprContext(expr) {
+			c.warnf(expr, "%s translated to json_extract_string, which returns JSON numbers as text, cast the result to compare it with numbers", function)
+			return
+		}
+	}
+}
+
+var (
+	jsonKey        = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	jsonObjectPath = regexp.MustCompile(`^\$(\.([A-Za-z_][A-Za-z0-9_]*|"[^"]*"))+$`)
+	jsonPathKey    = regexp.MustCompile(`\.(?:([A-Za-z_][A-Za-z0-9_]*)|"([^"]*)")`)
+)
+
+// jsonSet translates json_set into one json_merge_patch per path, the patch
+// being an object that nests the value under the keys of the path. Only
+// literal paths made of object keys can be expressed this way. Merging
+// differs from setting when the value is an object itself: keys already
+// present at the path are kept instead of being replaced.
+func (c *translatorCore) jsonSet(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) < 3 || len(args)%2 == 0 {
+		c.unsupportedf(call, "json_set takes a JSON value followed by path and value pairs")
+		return c.sourceText(call)
+	}
+
+	result := c.visitString(args[0])
+	for i := 1; i+1 < len(args); i += 2 {
+		path, value := args[i], args[i+1]
+
+		literal, ok := stringLiteral(path)
+		if !ok || !jsonObjectPath.MatchString(literal) {
+			c.unsupportedf(path, "json_set path %s has no DuckDB equivalent, only literal paths of object keys can be translated", c.sourceText(path))
+			continue
+		}
+		if literal := value.Literal_value(); literal != nil && literal.NULL_() != nil {
+			c.unsupportedf(value, "json_set to NULL has no DuckDB equivalent, a null in a merge patch removes the key")
+			continue
+		}
+		if function := value.Function_name(); function != nil && strings.HasPrefix(strings.ToLower(function.GetText()), "json") {
+			c.warnf(value, "json_set translated to json_merge_patch, an object value is merged with the one at the path instead of replacing it")
+		}
+
+		patch := c.visitString(value)
+		keys := jsonPathKey.FindAllStringSubmatch(literal, -1)
+		for j := len(keys) - 1; j >= 0; j-- {
+			key := keys[j][1] + keys[j][2]
+			patch = fmt.Sprintf("json_object(%s, %s)", quoteString(key), patch)
+		}
+		result = fmt.Sprintf("json_merge_patch(%s, %s)", result, patch)
+	}
+	return result
+}
+
+// jsonOperator translates the -> and ->> operators. SQLite reads a right
+// operand that is not a path as an object key or an array index, DuckDB
+// functions only take paths.
+func (c *translatorCore) jsonOperator(ctx *parser.ExprContext, operator string) string {
+	function := "json_extract"
+	if operator == "->>" {
+		function = "json_extract_string"
+		c.warnJSONText(ctx, operator)
+	}
+	return fmt.Sprintf("%s(%s, %s)", function, c.visitString(ctx.Expr(0)), c.jsonPath(ctx.Expr(1).(*parser.ExprContext)))
+}
+
+func (c *translatorCore) jsonPath(expr *parser.ExprContext) string {
+	if text, ok := stringLiteral(expr); ok {
+		switch {
+		case strings.HasPrefix(text, "$"):
+			return quoteString(text)
+		case jsonKey.MatchString(text):
+			return quoteString("$." + text)
+		case strings.Contains(text, `"`):
+			// A JSON pointer needs no quotes around the key
+			return quoteString("/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(text))
+		}
+		return quoteString(fmt.Sprintf(`$."%s"`, text))
+	}
+
+	if index, err := strconv.Atoi(expr.GetText()); err == nil {
+		if index < 0 {
+			return quoteString(fmt.Sprintf("$[#%d]", index))
+		}
+		return quoteString(fmt.Sprintf("$[%d]", index))
+	}
+
+	c.unsupportedf(expr, "JSON path %s is not a literal, it cannot be converted to a DuckDB path", c.sourceText(expr))
+	return c.visitString(expr)
+}
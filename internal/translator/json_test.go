@@ -0,0 +1,132 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestJSONFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "json_extract",
+			input:    "SELECT json_extract(data, '$.user.name') FROM events WHERE json_extract(data, '$.tags[0]') = 'x'",
+			expected: "SELECT json_extract_string(data, '$.user.name') FROM events WHERE json_extract_string(data, '$.tags[0]') = 'x'",
+		},
+		{
+			name:     "json_extract with several paths",
+			input:    "SELECT json_extract(data, '$.a', '$.b') FROM events",
+			expected: "SELECT to_json(json_extract(data, ['$.a', '$.b'])) FROM events",
+		},
+		{
+			name:     "unchanged functions",
+			input:    "SELECT json_object('id', id, 'tags', json_group_array(tag)) FROM tags GROUP BY id",
+			expected: "SELECT json_object('id', id, 'tags', json_group_array(tag)) FROM tags GROUP BY id",
+		},
+		{
+			name:     "json_set",
+			input:    "UPDATE users SET profile = json_set(profile, '$.address.city', ?, '$.\"last seen\"', 0)",
+			expected: "UPDATE users SET profile = json_merge_patch(json_merge_patch(profile, json_object('address', json_object('city', ?))), json_object('last seen', 0))",
+		},
+		{
+			name:     "json_set with an object value",
+			input:    "SELECT json_set(doc, '$.meta', json_object('v', 2)) FROM t",
+			expected: "SELECT json_merge_patch(doc, json_object('meta', json_object('v', 2))) FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "arrow operators",
+			input:    "SELECT data->'$.a', data->>'name', data -> 2, data->>-1, data->>'first name' FROM events",
+			expected: "SELECT json_extract(data, '$.a'), json_extract_string(data, '$.name'), json_extract(data, '$[2]'), json_extract_string(data, '$[#-1]'), json_extract_string(data, '$.\"first name\"') FROM events",
+		},
+		{
+			name:     "arrow precedence",
+			input:    "SELECT a->>'x' || 'y', a->'b'->>'c' = 1 FROM t",
+			expected: "SELECT json_extract_string(a, '$.x') || 'y', json_extract_string(json_extract(a, '$.b'), '$.c') = 1 FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "numbers extracted as text",
+			input:    "SELECT json_extract(d, '$.n') + 1, -(d->>'n'), d->>'n' BETWEEN 'a' AND 'b', d->>'n' IN (1, 2), d->>'n' IS NOT NULL FROM t",
+			expected: "SELECT json_extract_string(d, '$.n') + 1, -(json_extract_string(d, '$.n')), json_extract_string(d, '$.n') BETWEEN 'a' AND 'b', json_extract_string(d, '$.n') IN (1, 2), json_extract_string(d, '$.n') IS NOT NULL FROM t",
+			warnings: 3,
+		},
+		{
+			name:     "key with a double quote",
+			input:    "SELECT data->>'say \"hi\"/bye~' FROM events",
+			expected: "SELECT json_extract_string(data, '/say \"hi\"~1bye~0') FROM events",
+		},
+		{
+			name:     "json_each",
+			input:    "SELECT t.id, j.value FROM t, json_each(t.tags) AS j WHERE j.type = 'text'",
+			expected: "SELECT t.id, j.value FROM t, json_each(t.tags) AS j WHERE j.type = 'text'",
+		},
+		{
+			name:     "json_tree with a path in a join",
+			input:    "SELECT key FROM docs JOIN json_tree(docs.body, '$.items') WHERE atom IS NOT NULL",
+			expected: "SELECT key FROM docs JOIN json_tree(docs.body, '$.items') WHERE atom IS NOT NULL",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestJSONFunctionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{
+			name:    "json_set on an array element",
+			input:   "SELECT json_set(doc, '$.items[0]', 1) FROM t",
+			message: "json_set path '$.items[0]' has no DuckDB equivalent, only literal paths of object keys can be translated",
+		},
+		{
+			name:    "json_set to null",
+			input:   "SELECT json_set(doc, '$.a', NULL) FROM t",
+			message: "json_set to NULL has no DuckDB equivalent, a null in a merge patch removes the key",
+		},
+		{
+			name:    "arrow with a column path",
+			input:   "SELECT doc -> p FROM t",
+			message: "JSON path p is not a literal, it cannot be converted to a DuckDB path",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			core.Visit(createParseTree(t, &core, tt.input))
+
+			errs := core.diagnostics.errors()
+			if len(errs) != 1 || errs[0].Msg != tt.message {
+				t.Errorf("got diagnostics %v, want error %q", core.diagnostics, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package translator
+
+import (
+	"fmt"
+
+	"sql-translator/internal/parser"
+)
+
+// DuckDB operator precedence, from the loosest binding to the tightest. It
+// is PostgreSQL's, see
+// https://www.postgresql.org/docs/current/sql-syntax-lexical.html#SQL-PRECEDENCE,
+// with GLOB next to LIKE. It differs from SQLite's: || and the bitwise
+// operators bind more loosely than + and -, LIKE, IN and BETWEEN more
+// tightly than the comparisons, IS and the null tests more loosely, and the
+// comparisons do not associate.
+//
+// VisitExpr translates the tree SQLite builds, see repairExpressions, and
+// puts an operand in parentheses where DuckDB would otherwise read it
+// differently, so neither the two tables nor a translation that replaces an
+// operator changes the order of evaluation.
+const (
+	duckDBOr = iota
+	duckDBAnd
+	duckDBNot
+	duckDBIs
+	duckDBComparison
+	duckDBIn
+	duckDBOther
+	duckDBAdditive
+	duckDBMultiplicative
+	duckDBCollate
+	duckDBUnary
+	duckDBPrimary
+)
+
+// operand translates expr as the operand of an operator of the given
+// precedence, in parentheses if its outermost operator binds more loosely.
+// The operands an operator does not associate with take precedence+1.
+func (c *translatorCore) operand(expr parser.IExprContext, precedence int) string {
+	translated, exprPrecedence := c.expression(expr.(*parser.ExprContext))
+	if exprPrecedence < precedence {
+		return fmt.Sprintf("(%s)", translated)
+	}
+	return translated
+}
+
+// binaryPrecedence returns the DuckDB precedence of a binary operator kept
+// as it is and whether the operator associates to the left.
+func binaryPrecedence(operator string) (int, bool) {
+	switch operator {
+	case "OR":
+		return duckDBOr, true
+	case "AND":
+		return duckDBAnd, true
+	case "=", "==", "!=", "<>", "<", "<=", ">", ">=":
+		return duckDBComparison, false
+	case "+", "-":
+		return duckDBAdditive, true
+	case "*", "/", "%", "//":
+		return duckDBMultiplicative, true
+	}
+	// ||, <<, >>, & and |
+	return duckDBOther, true
+}
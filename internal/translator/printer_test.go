@@ -0,0 +1,77 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestOperatorPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		expected   string
+		arithmetic Arithmetic
+	}{
+		{
+			name:     "concatenation and bit operators",
+			input:    "SELECT 'n' || n + 1, a & b || c, a || b * c, a + b || c FROM t",
+			expected: "SELECT ('n' || n) + 1, a & (b || c), (a || b) * c, a + (b || c) FROM t",
+		},
+		{
+			name:     "comparisons",
+			input:    "SELECT a < b = c, a = b = c, a = (b = c), a < b AND c FROM t",
+			expected: "SELECT (a < b) = c, (a = b) = c, a = (b = c), a < b AND c FROM t",
+		},
+		{
+			name:     "pattern matching and comparisons",
+			input:    "SELECT a = b LIKE c, a LIKE b = c, a = b BETWEEN c AND d, a <> b IN (c, d) FROM t",
+			expected: "SELECT (a = b) ILIKE c, a ILIKE b = c, (a = b) BETWEEN c AND d, (a <> b) IN (c, d) FROM t",
+		},
+		{
+			name:     "one sqlite level, several duckdb levels",
+			input:    "SELECT a LIKE b LIKE c, a IN (1) BETWEEN b AND c, a ISNULL IN (b), a BETWEEN b AND c IS NULL, a = b = c IS d FROM t",
+			expected: "SELECT (a ILIKE b) ILIKE c, (a IN (1)) BETWEEN b AND c, (a IS NULL) IN (b), a BETWEEN b AND c IS NULL, (a = b) = c IS NOT DISTINCT FROM d FROM t",
+		},
+		{
+			name:     "is and null tests",
+			input:    "SELECT a IS b = c, a = b IS NULL, NOT a ISNULL, (a IS NULL) NOTNULL FROM t",
+			expected: "SELECT (a IS NOT DISTINCT FROM b) = c, a = b IS NULL, NOT a IS NULL, (a IS NULL) IS NOT NULL FROM t",
+		},
+		{
+			name:     "parentheses are kept",
+			input:    "SELECT (a + b) * c, a - (b - c), a - b - c, ((a + b)) FROM t",
+			expected: "SELECT (a + b) * c, a - (b - c), a - b - c, ((a + b)) FROM t",
+		},
+		{
+			name:       "rewritten operators",
+			input:      "SELECT likely(a + b) * 2, unlikely(NOT a) = b, a / b * 2, a || b / 2, x IN () + 1 FROM t",
+			expected:   "SELECT (a + b) * 2, (NOT a) = b, a // b * 2, (a || b) // 2, false + 1 FROM t",
+			arithmetic: ArithmeticInteger,
+		},
+		{
+			name:     "between bounds",
+			input:    "SELECT * FROM t WHERE a BETWEEN 1 AND 2 AND 3 AND b OR c NOT BETWEEN d + 1 AND e || f",
+			expected: "SELECT * FROM t WHERE a BETWEEN 1 AND 2 AND 3 AND b OR c NOT BETWEEN d + 1 AND e || f",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+				arithmetic:              tt.arithmetic,
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics %v", core.diagnostics)
+			}
+		})
+	}
+}
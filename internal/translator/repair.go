@@ -0,0 +1,362 @@
+package translator
+
+import (
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// repairCompounds undoes a misparse of compound SELECTs. The grammar accepts
+// keywords as table aliases and statements without a separating semicolon,
+// so `SELECT a FROM t UNION SELECT b FROM u` parses as two statements, the
+// first one aliasing t to UNION. SQLite reserves these keywords, the
+// compound is the only reading it has.
+func repairCompounds(tree antlr.ParseTree) {
+	parse, ok := tree.(*parser.ParseContext)
+	if !ok {
+		return
+	}
+
+	for merged := true; merged; {
+		merged = false
+		lists := parse.AllSql_stmt_list()
+		for i := 0; i+1 < len(lists) && !merged; i++ {
+			merged = mergeCompound(parse, lists[i], lists[i+1])
+		}
+	}
+}
+
+// mergeCompound moves the SELECT starting next onto the end of the last
+// statement of first if the two were split at a compound operator.
+func mergeCompound(parse *parser.ParseContext, first, next parser.ISql_stmt_listContext) bool {
+	last, ok := first.GetChild(first.GetChildCount() - 1).(*parser.Sql_stmtContext)
+	if !ok {
+		// A semicolon ends the list, the split is genuine
+		return false
+	}
+	table, owner := compoundAlias(last)
+	if table == nil {
+		return false
+	}
+	stmt, ok := next.GetChild(0).(*parser.Sql_stmtContext)
+	if !ok || stmt.GetChildCount() != 1 {
+		return false
+	}
+	tail, ok := stmt.GetChild(0).(*parser.Select_stmtContext)
+	if !ok || tail.Common_table_stmt() != nil {
+		return false
+	}
+
+	keyword := table.Table_alias().GetStart()
+	table.RemoveLastChild()
+	table.SetStop(lastToken(table))
+
+	op := parser.NewCompound_operatorContext(nil, owner, -1)
+	op.AddTokenNode(keyword)
+	op.SetStart(keyword)
+	op.SetStop(keyword)
+	owner.AddChild(op)
+	for _, child := range tail.GetChildren() {
+		child.(antlr.ParserRuleContext).SetParent(owner)
+		owner.AddChild(child.(antlr.RuleContext))
+	}
+	for node := antlr.Tree(owner); node != nil && node != parse; node = node.GetParent() {
+		node.(antlr.ParserRuleContext).SetStop(tail.GetStop())
+	}
+
+	// Whatever followed the merged SELECT continues the first list
+	for _, child := range next.GetChildren()[1:] {
+		appendChild(first, child)
+	}
+	first.SetStop(next.GetStop())
+
+	children := append([]antlr.Tree(nil), parse.GetChildren()...)
+	for range children {
+		parse.RemoveLastChild()
+	}
+	for _, child := range children {
+		if child != next {
+			appendChild(parse, child)
+		}
+	}
+	return true
+}
+
+// compoundAlias finds the table at the very end of stmt when its alias is a
+// compound operator keyword, along with the SELECT the table belongs to.
+func compoundAlias(stmt *parser.Sql_stmtContext) (*parser.Table_or_subqueryContext, *parser.Select_stmtContext) {
+	var owner *parser.Select_stmtContext
+	var node antlr.Tree = stmt
+	for node.GetChildCount() > 0 {
+		switch ctx := node.(type) {
+		case *parser.Select_stmtContext:
+			owner = ctx
+		case *parser.Table_or_subqueryContext:
+			alias, ok := ctx.GetChild(ctx.GetChildCount() - 1).(*parser.Table_aliasContext)
+			if !ok || owner == nil || ctx.AS_() != nil {
+				return nil, nil
+			}
+			switch alias.GetStart().GetTokenType() {
+			case parser.SQLiteParserUNION_, parser.SQLiteParserINTERSECT_, parser.SQLiteParserEXCEPT_:
+				return ctx, owner
+			}
+			return nil, nil
+		}
+		node = node.GetChild(node.GetChildCount() - 1)
+	}
+	return nil, nil
+}
+
+func appendChild(ctx antlr.ParserRuleContext, child antlr.Tree) {
+	switch child := child.(type) {
+	case antlr.TerminalNode:
+		ctx.AddTokenNode(child.GetSymbol())
+	case antlr.ParserRuleContext:
+		child.SetParent(ctx)
+		ctx.AddChild(child)
+	}
+}
+
+func lastToken(ctx antlr.ParserRuleContext) antlr.Token {
+	switch last := ctx.GetChild(ctx.GetChildCount() - 1).(type) {
+	case antlr.TerminalNode:
+		return last.GetSymbol()
+	case antlr.ParserRuleContext:
+		return last.GetStop()
+	}
+	return ctx.GetStop()
+}
+
+// SQLite operator precedence, from the loosest binding to the tightest, as
+// declared in SQLite's parse.y. IS, IN, LIKE, GLOB, MATCH, REGEXP, BETWEEN,
+// the null tests and the equality operators share one left associative
+// level.
+const (
+	precedenceOr = iota
+	precedenceAnd
+	precedenceNot
+	precedenceEquality
+	precedenceComparison
+	precedenceEscape
+	precedenceBitwise
+	precedenceAdditive
+	precedenceMultiplicative
+	precedenceConcat
+	precedenceCollate
+	precedenceUnary
+	precedencePrimary
+)
+
+// repairExpressions undoes misparses of expressions. The grammar gives the
+// NOT and NULL test forms of LIKE, BETWEEN, IN and ISNULL less precedence
+// than AND and OR, and a prefix NOT more than any binary operator, so
+// `a OR b NOT LIKE c` parses as `(a OR b) NOT LIKE c` and `NOT a = b` as
+// `(NOT a) = b`. The trees are reshaped to the ones SQLite builds. A result
+// column `NOT x` without AS, which parses as a column named NOT aliased x,
+// becomes the NOT of x.
+func repairExpressions(tree antlr.Tree) {
+	if column, ok := tree.(*parser.Result_columnContext); ok {
+		repairNotAlias(column)
+	}
+
+	children := tree.GetChildren()
+	for i, child := range children {
+		repairExpressions(child)
+		if expr, ok := child.(*parser.ExprContext); ok {
+			root := reassociate(expr)
+			root.SetParent(tree)
+			children[i] = root
+			if core, ok := tree.(*parser.Select_coreContext); ok {
+				relabel(core, expr, root)
+			}
+		}
+	}
+}
+
+// relabel points the labelled expressions of a select core that were
+// replaced by repairExpressions to their new root.
+func relabel(core *parser.Select_coreContext, old, root *parser.ExprContext) {
+	if core.GetWhereExpr() == old {
+		core.SetWhereExpr(root)
+	}
+	if core.GetHavingExpr() == old {
+		core.SetHavingExpr(root)
+	}
+	for i, expr := range core.GetGroupByExpr() {
+		if expr == old {
+			core.GetGroupByExpr()[i] = root
+		}
+	}
+}
+
+// reassociate rotates expr and its operands until no operand binds more
+// loosely than the operator applied to it, and returns the new root.
+func reassociate(expr *parser.ExprContext) *parser.ExprContext {
+	expr = repairBetween(expr)
+	precedence := exprPrecedence(expr)
+	if precedence == precedencePrimary {
+		return expr
+	}
+
+	// (a OP1 b) OP2 c where OP1 binds more loosely: a OP1 (b OP2 c)
+	if left := leftOperand(expr); left != nil && exprPrecedence(left) < precedence && rightOperand(left) != nil {
+		setLeftOperand(expr, rightOperand(left))
+		setRightOperand(left, reassociate(expr))
+		return reassociate(left)
+	}
+
+	// a OP1 (b OP2 c) where OP2 binds as loosely or more: (a OP1 b) OP2 c.
+	// Prefix operators take operands of their own precedence.
+	if right := rightOperand(expr); right != nil && leftOperand(right) != nil {
+		rightPrecedence := exprPrecedence(right)
+		if rightPrecedence < precedence || rightPrecedence == precedence && leftOperand(expr) != nil {
+			setRightOperand(expr, leftOperand(right))
+			setLeftOperand(right, reassociate(expr))
+			return reassociate(right)
+		}
+	}
+	return expr
+}
+
+// repairBetween moves an AND out of the lower bound of a BETWEEN, which
+// the grammar reads `a BETWEEN 1 AND 2 AND b` with, to get
+// `(a BETWEEN 1 AND 2) AND b`, and returns the new root.
+func repairBetween(expr *parser.ExprContext) *parser.ExprContext {
+	exprs := expr.AllExpr()
+	if expr.BETWEEN_() == nil || len(exprs) != 3 {
+		return expr
+	}
+	and := exprs[1].(*parser.ExprContext)
+	if exprPrecedence(and) != precedenceAnd {
+		return expr
+	}
+
+	upper := rightOperand(expr)
+	for i, child := range expr.GetChildren() {
+		if child == and {
+			expr.GetChildren()[i] = leftOperand(and)
+			leftOperand(and).SetParent(expr)
+		}
+	}
+	setRightOperand(expr, rightOperand(and))
+	setLeftOperand(and, repairBetween(expr))
+	setRightOperand(and, upper)
+	return and
+}
+
+// exprPrecedence returns how tightly the operator of expr binds.
+func exprPrecedence(expr *parser.ExprContext) int {
+	if operator := expr.Unary_operator(); operator != nil {
+		if operator.NOT_() != nil {
+			return precedenceNot
+		}
+		return precedenceUnary
+	}
+	if leftOperand(expr) == nil {
+		return precedencePrimary
+	}
+
+	operator, ok := expr.GetChild(1).(antlr.TerminalNode)
+	if !ok {
+		return precedencePrimary
+	}
+	tokenType := operator.GetSymbol().GetTokenType()
+	if tokenType == parser.SQLiteParserNOT_ {
+		// NOT NULL, NOT IN, NOT LIKE and the like
+		return precedenceEquality
+	}
+
+	switch tokenType {
+	case parser.SQLiteParserOR_:
+		return precedenceOr
+	case parser.SQLiteParserAND_:
+		return precedenceAnd
+	case parser.SQLiteParserASSIGN, parser.SQLiteParserEQ, parser.SQLiteParserNOT_EQ1, parser.SQLiteParserNOT_EQ2,
+		parser.SQLiteParserIS_, parser.SQLiteParserISNULL_, parser.SQLiteParserNOTNULL_, parser.SQLiteParserIN_,
+		parser.SQLiteParserLIKE_, parser.SQLiteParserGLOB_, parser.SQLiteParserMATCH_, parser.SQLiteParserREGEXP_,
+		parser.SQLiteParserBETWEEN_:
+		return precedenceEquality
+	case parser.SQLiteParserLT, parser.SQLiteParserLT_EQ, parser.SQLiteParserGT, parser.SQLiteParserGT_EQ:
+		return precedenceComparison
+	case parser.SQLiteParserLT2, parser.SQLiteParserGT2, parser.SQLiteParserAMP, parser.SQLiteParserPIPE:
+		return precedenceBitwise
+	case parser.SQLiteParserPLUS, parser.SQLiteParserMINUS:
+		return precedenceAdditive
+	case parser.SQLiteParserSTAR, parser.SQLiteParserDIV, parser.SQLiteParserMOD:
+		return precedenceMultiplicative
+	case parser.SQLiteParserPIPE2:
+		return precedenceConcat
+	case parser.SQLiteParserCOLLATE_:
+		return precedenceCollate
+	}
+	return precedencePrimary
+}
+
+// leftOperand returns the operand before the operator of a binary or
+// postfix expression.
+func leftOperand(expr *parser.ExprContext) *parser.ExprContext {
+	left, _ := expr.GetChild(0).(*parser.ExprContext)
+	return left
+}
+
+// rightOperand returns the operand after the operator of a binary or
+// prefix expression, the last child when it is an expression.
+func rightOperand(expr *parser.ExprContext) *parser.ExprContext {
+	right, _ := expr.GetChild(expr.GetChildCount() - 1).(*parser.ExprContext)
+	return right
+}
+
+func setLeftOperand(expr, operand *parser.ExprContext) {
+	expr.GetChildren()[0] = operand
+	operand.SetParent(expr)
+	expr.SetStart(operand.GetStart())
+}
+
+func setRightOperand(expr, operand *parser.ExprContext) {
+	expr.GetChildren()[expr.GetChildCount()-1] = operand
+	operand.SetParent(expr)
+	expr.SetStop(operand.GetStop())
+}
+
+// repairNotAlias turns a result column parsed as a column named NOT with
+// an alias into the NOT of the aliased name.
+func repairNotAlias(column *parser.Result_columnContext) {
+	alias := column.Column_alias()
+	if column.AS_() != nil || alias == nil || alias.GetStart().GetTokenType() != parser.SQLiteParserIDENTIFIER {
+		return
+	}
+
+	// The NOT is the last operand of the result expression
+	not, _ := column.Expr().(*parser.ExprContext)
+	for not != nil && (not.Column_name() == nil || not.GetChildCount() != 1) {
+		not = rightOperand(not)
+	}
+	if not == nil || not.GetStart().GetTokenType() != parser.SQLiteParserNOT_ {
+		return
+	}
+	name := alias.GetStart()
+
+	operator := parser.NewUnary_operatorContext(nil, not, -1)
+	operator.AddTokenNode(not.GetStart())
+	operator.SetStart(not.GetStart())
+	operator.SetStop(not.GetStart())
+
+	anyName := parser.NewAny_nameContext(nil, nil, -1)
+	anyName.AddTokenNode(name)
+	columnName := parser.NewColumn_nameContext(nil, nil, -1)
+	appendChild(columnName, anyName)
+	operand := parser.NewExprContext(nil, not, -1)
+	appendChild(operand, columnName)
+	for _, ctx := range []antlr.ParserRuleContext{anyName, columnName, operand} {
+		ctx.SetStart(name)
+		ctx.SetStop(name)
+	}
+
+	not.RemoveLastChild()
+	appendChild(not, operator)
+	appendChild(not, operand)
+	for node := antlr.Tree(not); node != nil && node != column.GetParent(); node = node.GetParent() {
+		node.(antlr.ParserRuleContext).SetStop(name)
+	}
+	column.RemoveLastChild()
+}
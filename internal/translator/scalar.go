@@ -0,0 +1,108 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+)
+
+// Scalar functions DuckDB lacks or whose results differ from SQLite's. nullif
+// and coalesce behave the same and are emitted unchanged.
+
+func init() {
+	registerFunctions(map[string]functionTranslator{
+		"ifnull":     (*translatorCore).ifnull,
+		"iif":        (*translatorCore).iif,
+		"typeof":     (*translatorCore).typeOf,
+		"likely":     (*translatorCore).likelihood,
+		"unlikely":   (*translatorCore).likelihood,
+		"likelihood": (*translatorCore).likelihood,
+		"random":     (*translatorCore).random,
+		"randomblob": (*translatorCore).randomblob,
+		"zeroblob":   (*translatorCore).zeroblob,
+	})
+}
+
+func (c *translatorCore) ifnull(call *parser.ExprContext) string {
+	return fmt.Sprintf("coalesce(%s)", strings.Join(c.functionArgs(call), ", "))
+}
+
+// iif translates iif, whose else branch is optional since SQLite 3.48.
+func (c *translatorCore) iif(call *parser.ExprContext) string {
+	args := c.functionArgs(call)
+	switch len(args) {
+	case 2:
+		return fmt.Sprintf("CASE WHEN %s THEN %s END", args[0], args[1])
+	case 3:
+		return fmt.Sprintf("CASE WHEN %s THEN %s ELSE %s END", args[0], args[1], args[2])
+	}
+	c.unsupportedf(call, "iif takes a condition, a value and an optional else value")
+	return c.sourceText(call)
+}
+
+// typeOf maps the DuckDB type of a value to the SQLite storage class it
+// would have: null, integer, real, text or blob.
+func (c *translatorCore) typeOf(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) != 1 {
+		c.unsupportedf(call, "typeof takes exactly one argument")
+		return c.sourceText(call)
+	}
+
+	return c.evaluateOnce(args[0], "CASE WHEN %[1]s IS NULL THEN 'null' "+
+		"WHEN typeof(%[1]s) IN ('BOOLEAN', 'TINYINT', 'SMALLINT', 'INTEGER', 'BIGINT', 'HUGEINT', 'UTINYINT', 'USMALLINT', 'UINTEGER', 'UBIGINT') THEN 'integer' "+
+		"WHEN typeof(%[1]s) IN ('FLOAT', 'DOUBLE') OR typeof(%[1]s) LIKE 'DECIMAL%%' THEN 'real' "+
+		"WHEN typeof(%[1]s) = 'BLOB' THEN 'blob' "+
+		"ELSE 'text' END")
+}
+
+// evaluateOnce fills format, which refers to the value of expr as %[1]s
+// as often as it needs, so that expr is evaluated once. Columns, literals
+// and bind parameters are used as they are, anything else, which may be
+// volatile or costly, is bound to the parameter of a lambda.
+func (c *translatorCore) evaluateOnce(expr parser.IExprContext, format string) string {
+	if expr.Column_name() != nil || expr.Literal_value() != nil || expr.BIND_PARAMETER() != nil {
+		return fmt.Sprintf(format, c.visitString(expr))
+	}
+	return fmt.Sprintf("list_transform([%s], v -> %s)[1]", c.visitString(expr), fmt.Sprintf(format, "v"))
+}
+
+// likelihood drops the planner hints likely, unlikely and likelihood, which
+// return their first argument.
+func (c *translatorCore) likelihood(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) == 0 {
+		c.unsupportedf(call, "%s takes the expression it applies to", c.sourceText(call.Function_name()))
+		return c.sourceText(call)
+	}
+	// The call takes the place of a function call in any expression
+	return c.operand(args[0], duckDBPrimary)
+}
+
+// random translates random, which gives a signed 64-bit integer in SQLite
+// and a double between 0 and 1 in DuckDB.
+func (c *translatorCore) random(call *parser.ExprContext) string {
+	return "CAST(floor((random() * 2 - 1) * 9223372036854775807) AS BIGINT)"
+}
+
+// randomblob builds a blob of random bytes, at least one as in SQLite. The
+// bytes are drawn in a lambda, which DuckDB evaluates for each element of
+// each row.
+func (c *translatorCore) randomblob(call *parser.ExprContext) string {
+	args := c.functionArgs(call)
+	if len(args) != 1 {
+		c.unsupportedf(call, "randomblob takes exactly one argument")
+		return c.sourceText(call)
+	}
+	return fmt.Sprintf("unhex(array_to_string(list_transform(range(greatest(%s, 1)), i -> lpad(hex(CAST(floor(random() * 256) AS INTEGER)), 2, '0')), ''))", args[0])
+}
+
+func (c *translatorCore) zeroblob(call *parser.ExprContext) string {
+	args := c.functionArgs(call)
+	if len(args) != 1 {
+		c.unsupportedf(call, "zeroblob takes exactly one argument")
+		return c.sourceText(call)
+	}
+	return fmt.Sprintf("unhex(repeat('00', %s))", args[0])
+}
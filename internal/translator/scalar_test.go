@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestScalarFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "ifnull and nullif",
+			input:    "SELECT ifnull(nick, name), nullif(a, 0) FROM users",
+			expected: "SELECT coalesce(nick, name), nullif(a, 0) FROM users",
+		},
+		{
+			name:     "iif",
+			input:    "SELECT iif(a > 0, 'pos', 'neg'), iif(b, 1) FROM t",
+			expected: "SELECT CASE WHEN a > 0 THEN 'pos' ELSE 'neg' END, CASE WHEN b THEN 1 END FROM t",
+		},
+		{
+			name:     "typeof",
+			input:    "SELECT typeof(v) FROM t",
+			expected: "SELECT CASE WHEN v IS NULL THEN 'null' WHEN typeof(v) IN ('BOOLEAN', 'TINYINT', 'SMALLINT', 'INTEGER', 'BIGINT', 'HUGEINT', 'UTINYINT', 'USMALLINT', 'UINTEGER', 'UBIGINT') THEN 'integer' WHEN typeof(v) IN ('FLOAT', 'DOUBLE') OR typeof(v) LIKE 'DECIMAL%' THEN 'real' WHEN typeof(v) = 'BLOB' THEN 'blob' ELSE 'text' END FROM t",
+		},
+		{
+			name:     "typeof of a call",
+			input:    "SELECT typeof(random()) FROM t",
+			expected: "SELECT list_transform([CAST(floor((random() * 2 - 1) * 9223372036854775807) AS BIGINT)], v -> CASE WHEN v IS NULL THEN 'null' WHEN typeof(v) IN ('BOOLEAN', 'TINYINT', 'SMALLINT', 'INTEGER', 'BIGINT', 'HUGEINT', 'UTINYINT', 'USMALLINT', 'UINTEGER', 'UBIGINT') THEN 'integer' WHEN typeof(v) IN ('FLOAT', 'DOUBLE') OR typeof(v) LIKE 'DECIMAL%' THEN 'real' WHEN typeof(v) = 'BLOB' THEN 'blob' ELSE 'text' END)[1] FROM t",
+		},
+		{
+			name:     "planner hints",
+			input:    "SELECT * FROM t WHERE likely(a OR b) AND unlikely(t.c) AND likelihood(d > 2, 0.25)",
+			expected: "SELECT * FROM t WHERE (a OR b) AND t.c AND (d > 2)",
+		},
+		{
+			name:     "random",
+			input:    "SELECT random() FROM t",
+			expected: "SELECT CAST(floor((random() * 2 - 1) * 9223372036854775807) AS BIGINT) FROM t",
+		},
+		{
+			name:     "blobs",
+			input:    "SELECT randomblob(16), zeroblob(n) FROM t",
+			expected: "SELECT unhex(array_to_string(list_transform(range(greatest(16, 1)), i -> lpad(hex(CAST(floor(random() * 256) AS INTEGER)), 2, '0')), '')), unhex(repeat('00', n)) FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics %v", core.diagnostics)
+			}
+		})
+	}
+}
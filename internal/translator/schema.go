@@ -0,0 +1,159 @@
+package translator
+
+import (
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// tableSchema is what the translator knows about a table from a CREATE TABLE
+// statement seen earlier in the input.
+type tableSchema struct {
+	// columns maps lower-case column names to their declared SQLite type
+	columns map[string]string
+	// rowidAlias is the INTEGER PRIMARY KEY column, if the table has one
+	rowidAlias string
+}
+
+// scopeTable is a table visible to column references in a statement.
+type scopeTable struct {
+	name  string
+	alias string
+}
+
+func (c *translatorCore) tableSchema(name string) *tableSchema {
+	return c.schema[strings.ToLower(unquoteIdentifier(name))]
+}
+
+func (c *translatorCore) defineTable(name string, table *tableSchema) {
+	if c.schema == nil {
+		c.schema = make(map[string]*tableSchema)
+	}
+	c.schema[strings.ToLower(unquoteIdentifier(name))] = table
+}
+
+func (c *translatorCore) pushScope(tables []scopeTable) {
+	c.scopes = append(c.scopes, tables)
+}
+
+func (c *translatorCore) popScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// resolveTable finds the table a column reference belongs to. A qualified
+// reference is looked up by alias from the innermost scope outwards, an
+// unqualified one only resolves when the innermost scope has a single table.
+func (c *translatorCore) resolveTable(qualifier string) (scopeTable, bool) {
+	if qualifier == "" {
+		if len(c.scopes) > 0 && len(c.scopes[len(c.scopes)-1]) == 1 {
+			return c.scopes[len(c.scopes)-1][0], true
+		}
+		return scopeTable{}, false
+	}
+
+	qualifier = strings.ToLower(unquoteIdentifier(qualifier))
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		for _, table := range c.scopes[i] {
+			if table.alias == qualifier {
+				return table, true
+			}
+		}
+	}
+	return scopeTable{}, false
+}
+
+// rowidColumn rewrites a reference to one of SQLite's implicit rowid columns.
+// Tables with an INTEGER PRIMARY KEY use that column, anything else falls
+// back to DuckDB's rowid pseudo-column. ok is false for ordinary columns.
+func (c *translatorCore) rowidColumn(qualifier, column string) (string, bool) {
+	switch strings.ToLower(unquoteIdentifier(column)) {
+	case "rowid", "oid", "_rowid_":
+	default:
+		return "", false
+	}
+
+	if table, found := c.resolveTable(qualifier); found {
+		if schema := c.schema[table.name]; schema != nil {
+			// A declared column shadows the implicit one
+			if _, declared := schema.columns[strings.ToLower(unquoteIdentifier(column))]; declared {
+				return "", false
+			}
+			if schema.rowidAlias != "" {
+				return schema.rowidAlias, true
+			}
+		}
+	}
+	return "rowid", true
+}
+
+// fromScope lists the tables named directly in a FROM clause. Subqueries
+// get their own scope when they are visited.
+func fromScope(ctx *parser.Select_coreContext) []scopeTable {
+	return tablesScope(ctx.AllTable_or_subquery(), ctx.Join_clause())
+}
+
+// tablesScope lists the tables named in a comma separated list or a join
+// clause, descending into parenthesised ones.
+func tablesScope(from []parser.ITable_or_subqueryContext, join parser.IJoin_clauseContext) []scopeTable {
+	var tables []scopeTable
+	var collect func(table parser.ITable_or_subqueryContext)
+	collect = func(table parser.ITable_or_subqueryContext) {
+		var name antlr.ParserRuleContext
+		switch {
+		case table.Table_name() != nil:
+			name = table.Table_name()
+		case table.Table_function_name() != nil:
+			// A table function is not a table of the schema but still
+			// takes part in resolving unqualified columns
+			name = table.Table_function_name()
+		}
+		if name != nil {
+			scoped := scopeTable{name: strings.ToLower(unquoteIdentifier(name.GetText()))}
+			scoped.alias = scoped.name
+			if alias := table.Table_alias(); alias != nil && joinKeywordAlias(table.(*parser.Table_or_subqueryContext)) == "" {
+				scoped.alias = strings.ToLower(unquoteIdentifier(alias.GetText()))
+			}
+			tables = append(tables, scoped)
+			return
+		}
+		for _, nested := range table.AllTable_or_subquery() {
+			collect(nested)
+		}
+		if join := table.Join_clause(); join != nil {
+			for _, nested := range join.AllTable_or_subquery() {
+				collect(nested)
+			}
+		}
+	}
+
+	for _, table := range from {
+		collect(table)
+	}
+	if join != nil {
+		for _, table := range join.AllTable_or_subquery() {
+			collect(table)
+		}
+	}
+	return tables
+}
+
+// unquoteIdentifier strips SQLite identifier quoting of any style.
+func unquoteIdentifier(text string) string {
+	if len(text) < 2 {
+		return text
+	}
+
+	switch text[0] {
+	case '"':
+		return strings.ReplaceAll(text[1:len(text)-1], `""`, `"`)
+	case '[':
+		return text[1 : len(text)-1]
+	case '`':
+		return strings.ReplaceAll(text[1:len(text)-1], "``", "`")
+	case '\'':
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return text
+}
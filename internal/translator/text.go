@@ -0,0 +1,205 @@
+package translator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sql-translator/internal/parser"
+)
+
+// Most string functions exist in DuckDB under the same name and with the
+// same semantics: substr and substring, including SQLite's handling of zero
+// and negative starts and lengths, instr, unicode, replace, upper, lower,
+// length, and ltrim, rtrim and trim with a character set.
+
+func init() {
+	registerFunctions(map[string]functionTranslator{
+		"printf":  (*translatorCore).printf,
+		"format":  (*translatorCore).printf,
+		"char":    (*translatorCore).char,
+		"hex":     (*translatorCore).hex,
+		"quote":   (*translatorCore).quote,
+		"glob":    (*translatorCore).glob,
+		"like":    (*translatorCore).like,
+		"soundex": (*translatorCore).soundex,
+	})
+}
+
+// printfConversion matches one conversion of a printf format.
+var printfConversion = regexp.MustCompile(`%[-+ 0#,!]*(\d+|\*)?(\.(\d+|\*))?(ll|l)?(.)`)
+
+// printfConversions are the conversions DuckDB's printf formats like
+// SQLite's. The others are SQLite extensions: %q, %Q and %w quote SQL text,
+// %z frees its argument, and %c prints the first character of text.
+const printfConversions = "diufFeEgGxXos%"
+
+// printf translates printf and its alias format. DuckDB's format function
+// takes {} placeholders, its printf takes SQLite's conversions.
+func (c *translatorCore) printf(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) == 0 {
+		return "printf()"
+	}
+
+	if format, ok := stringLiteral(args[0]); ok {
+		for _, m := range printfConversion.FindAllStringSubmatch(format, -1) {
+			if !strings.Contains(printfConversions, m[5]) || strings.ContainsAny(m[0], ",!") {
+				c.unsupportedf(args[0], "printf conversion %s has no DuckDB equivalent", m[0])
+			}
+		}
+	} else {
+		c.warnf(args[0], "printf format %s is not a literal, SQLite specific conversions in it are not checked", c.sourceText(args[0]))
+	}
+
+	return fmt.Sprintf("printf(%s)", strings.Join(c.functionArgs(call), ", "))
+}
+
+// char builds a string from code points, DuckDB's chr takes a single one.
+func (c *translatorCore) char(call *parser.ExprContext) string {
+	var chars []string
+	for _, arg := range c.functionArgs(call) {
+		chars = append(chars, fmt.Sprintf("chr(%s)", arg))
+	}
+
+	switch len(chars) {
+	case 0:
+		return "''"
+	case 1:
+		return chars[0]
+	}
+	return fmt.Sprintf("concat(%s)", strings.Join(chars, ", "))
+}
+
+// hex translates hex, which in SQLite encodes the text of any value but a
+// blob while DuckDB's encodes numbers themselves. Number literals are
+// passed as text, other values are cast to text unless they are blobs or
+// text already.
+func (c *translatorCore) hex(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) != 1 {
+		return fmt.Sprintf("hex(%s)", strings.Join(c.functionArgs(call), ", "))
+	}
+
+	arg := args[0]
+	if literal := arg.Literal_value(); literal != nil {
+		if literal.NUMERIC_LITERAL() != nil {
+			return fmt.Sprintf("hex(%s)", quoteString(literal.GetText()))
+		}
+		return fmt.Sprintf("hex(%s)", c.visitString(arg))
+	}
+	if c.numberKind(arg) != unknownNumber {
+		return fmt.Sprintf("hex(CAST(%s AS VARCHAR))", c.visitString(arg))
+	}
+	return c.evaluateOnce(arg, "CASE WHEN typeof(%[1]s) IN ('VARCHAR', 'BLOB') THEN hex(%[1]s) ELSE hex(CAST(%[1]s AS VARCHAR)) END")
+}
+
+// quote renders a value as an SQL literal. DuckDB has no such function, the
+// literal is built according to the type of the value.
+func (c *translatorCore) quote(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) != 1 {
+		c.unsupportedf(call, "quote takes exactly one argument")
+		return c.sourceText(call)
+	}
+
+	if text, ok := stringLiteral(args[0]); ok {
+		return quoteString(quoteString(text))
+	}
+
+	return c.evaluateOnce(args[0], "CASE WHEN %[1]s IS NULL THEN 'NULL' "+
+		"WHEN typeof(%[1]s) = 'VARCHAR' THEN concat('''', replace(%[1]s, '''', ''''''), '''') "+
+		"WHEN typeof(%[1]s) = 'BLOB' THEN concat('X''', hex(%[1]s), '''') "+
+		"ELSE CAST(%[1]s AS VARCHAR) END")
+}
+
+// patternMatch translates LIKE and GLOB. SQLite's LIKE ignores the case of
+// ASCII letters unless PRAGMA case_sensitive_like is on, DuckDB's ILIKE does
+// the same for all letters. GLOB is case sensitive in both.
+func (c *translatorCore) patternMatch(ctx *parser.ExprContext) string {
+	exprs := ctx.AllExpr()
+	value := c.operand(exprs[0], duckDBIn+1)
+
+	var operator, pattern string
+	if ctx.GLOB_() != nil {
+		operator, pattern = "GLOB", c.globPattern(exprs[1])
+		if ctx.ESCAPE_() != nil {
+			c.unsupportedf(ctx, "GLOB has no escape character")
+		}
+	} else {
+		operator, pattern = c.likeOperator(), c.operand(exprs[1], duckDBIn+1)
+	}
+	if ctx.NOT_() != nil {
+		operator = "NOT " + operator
+	}
+
+	match := fmt.Sprintf("%s %s %s", value, operator, pattern)
+	if ctx.ESCAPE_() != nil && ctx.LIKE_() != nil {
+		match = fmt.Sprintf("%s ESCAPE %s", match, c.operand(exprs[2], duckDBIn+1))
+	}
+	return match
+}
+
+func (c *translatorCore) likeOperator() string {
+	if c.caseSensitiveLike {
+		return "LIKE"
+	}
+	return "ILIKE"
+}
+
+// like translates the function form of LIKE, which takes the pattern first
+// and an optional escape character last.
+func (c *translatorCore) like(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	switch len(args) {
+	case 2:
+		return fmt.Sprintf("(%s %s %s)", c.operand(args[1], duckDBIn+1), c.likeOperator(), c.operand(args[0], duckDBIn+1))
+	case 3:
+		return fmt.Sprintf("(%s %s %s ESCAPE %s)", c.operand(args[1], duckDBIn+1), c.likeOperator(), c.operand(args[0], duckDBIn+1), c.operand(args[2], duckDBIn+1))
+	}
+	c.unsupportedf(call, "like takes a pattern, a string and an optional escape character")
+	return c.sourceText(call)
+}
+
+// glob translates the function form of GLOB, which takes the pattern first.
+func (c *translatorCore) glob(call *parser.ExprContext) string {
+	args := call.AllExpr()
+	if len(args) != 2 {
+		c.unsupportedf(call, "glob takes a pattern and a string")
+		return c.sourceText(call)
+	}
+	return fmt.Sprintf("(%s GLOB %s)", c.operand(args[1], duckDBIn+1), c.globPattern(args[0]))
+}
+
+// globPattern translates a GLOB pattern. SQLite negates a character class
+// with ^ where DuckDB uses !.
+func (c *translatorCore) globPattern(pattern parser.IExprContext) string {
+	if text, ok := stringLiteral(pattern); ok {
+		return quoteString(strings.ReplaceAll(text, "[^", "[!"))
+	}
+	return c.operand(pattern, duckDBIn+1)
+}
+
+func (c *translatorCore) soundex(call *parser.ExprContext) string {
+	c.unsupportedf(call, "soundex has no DuckDB equivalent")
+	return c.sourceText(call)
+}
+
+// VisitPragma_stmt handles PRAGMA case_sensitive_like, which changes how
+// the LIKE operators that follow translate and is then dropped. DuckDB has
+// no equivalent for the other pragmas.
+func (c *translatorCore) VisitPragma_stmt(ctx *parser.Pragma_stmtContext) any {
+	if !strings.EqualFold(unquoteIdentifier(ctx.Pragma_name().GetText()), "case_sensitive_like") || ctx.Pragma_value() == nil {
+		return c.unsupported(ctx)
+	}
+
+	switch value := strings.ToLower(unquoteIdentifier(ctx.Pragma_value().GetText())); value {
+	case "1", "on", "true", "yes":
+		c.caseSensitiveLike = true
+	case "0", "off", "false", "no":
+		c.caseSensitiveLike = false
+	default:
+		c.unsupportedf(ctx, "PRAGMA case_sensitive_like value %s is not a boolean", value)
+	}
+	return ""
+}
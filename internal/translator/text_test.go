@@ -0,0 +1,187 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestStringFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		warnings int
+	}{
+		{
+			name:     "unchanged functions",
+			input:    "SELECT substr(name, -3), substring(name, 0, 2), instr(name, 'a'), unicode(name), replace(name, 'a', 'b'), ltrim(name, 'xy'), trim(name, ' ') FROM users",
+			expected: "SELECT substr(name, -3), substring(name, 0, 2), instr(name, 'a'), unicode(name), replace(name, 'a', 'b'), ltrim(name, 'xy'), trim(name, ' ') FROM users",
+		},
+		{
+			name:     "printf and format",
+			input:    "SELECT printf('%05.2f %s', price, name), format('%d%%', pct) FROM items",
+			expected: "SELECT printf('%05.2f %s', price, name), printf('%d%%', pct) FROM items",
+		},
+		{
+			name:     "printf with a column format",
+			input:    "SELECT printf(fmt, a) FROM t",
+			expected: "SELECT printf(fmt, a) FROM t",
+			warnings: 1,
+		},
+		{
+			name:     "char",
+			input:    "SELECT char(72, 105), char(code), char() FROM t",
+			expected: "SELECT concat(chr(72), chr(105)), chr(code), '' FROM t",
+		},
+		{
+			name:  "hex",
+			input: "SELECT hex(255), hex(1 + 1), hex(X'ff'), hex(name), hex(upper(name)) FROM t",
+			expected: "SELECT hex('255'), hex(CAST(1 + 1 AS VARCHAR)), hex(X'ff'), " +
+				"CASE WHEN typeof(name) IN ('VARCHAR', 'BLOB') THEN hex(name) ELSE hex(CAST(name AS VARCHAR)) END, " +
+				"list_transform([upper(name)], v -> CASE WHEN typeof(v) IN ('VARCHAR', 'BLOB') THEN hex(v) ELSE hex(CAST(v AS VARCHAR)) END)[1] FROM t",
+		},
+		{
+			name:     "quote of a literal",
+			input:    "SELECT quote('it''s')",
+			expected: "SELECT '''it''''s'''",
+		},
+		{
+			name:     "quote of a column",
+			input:    "SELECT quote(v) FROM t",
+			expected: "SELECT CASE WHEN v IS NULL THEN 'NULL' WHEN typeof(v) = 'VARCHAR' THEN concat('''', replace(v, '''', ''''''), '''') WHEN typeof(v) = 'BLOB' THEN concat('X''', hex(v), '''') ELSE CAST(v AS VARCHAR) END FROM t",
+		},
+		{
+			name:     "quote of an expression",
+			input:    "SELECT quote(a || b) FROM t",
+			expected: "SELECT list_transform([a || b], v -> CASE WHEN v IS NULL THEN 'NULL' WHEN typeof(v) = 'VARCHAR' THEN concat('''', replace(v, '''', ''''''), '''') WHEN typeof(v) = 'BLOB' THEN concat('X''', hex(v), '''') ELSE CAST(v AS VARCHAR) END)[1] FROM t",
+		},
+		{
+			name:     "glob function",
+			input:    "SELECT * FROM files WHERE glob('*.[^ch]', path)",
+			expected: "SELECT * FROM files WHERE (path GLOB '*.[!ch]')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) != tt.warnings || len(core.diagnostics.errors()) > 0 {
+				t.Errorf("got diagnostics %v, want %d warnings", core.diagnostics, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestPatternMatching(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		caseSensitive bool
+		expected      string
+	}{
+		{
+			name:     "like",
+			input:    "SELECT * FROM users WHERE name LIKE 'a%' AND email NOT LIKE '%@example.com'",
+			expected: "SELECT * FROM users WHERE name ILIKE 'a%' AND email NOT ILIKE '%@example.com'",
+		},
+		{
+			name:     "like with an escape",
+			input:    "SELECT * FROM t WHERE code LIKE '10\\%%' ESCAPE '\\'",
+			expected: "SELECT * FROM t WHERE code ILIKE '10\\%%' ESCAPE '\\'",
+		},
+		{
+			name:          "case sensitive like",
+			input:         "SELECT * FROM users WHERE name LIKE 'A%' OR like('%z', name)",
+			caseSensitive: true,
+			expected:      "SELECT * FROM users WHERE name LIKE 'A%' OR (name LIKE '%z')",
+		},
+		{
+			name:     "like function",
+			input:    "SELECT like('a!%', name, '!') FROM users",
+			expected: "SELECT (name ILIKE 'a!%' ESCAPE '!') FROM users",
+		},
+		{
+			name:     "pragma",
+			input:    "SELECT a LIKE 'x'; PRAGMA case_sensitive_like = ON; SELECT a LIKE 'x'; PRAGMA case_sensitive_like(false); SELECT a LIKE 'x'",
+			expected: "SELECT a ILIKE 'x';\nSELECT a LIKE 'x';\nSELECT a ILIKE 'x'",
+		},
+		{
+			name:     "glob",
+			input:    "SELECT * FROM files WHERE path GLOB '*.go' AND name NOT GLOB '[^a-z]*'",
+			expected: "SELECT * FROM files WHERE path GLOB '*.go' AND name NOT GLOB '[!a-z]*'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+				caseSensitiveLike:       tt.caseSensitive,
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics %v", core.diagnostics)
+			}
+		})
+	}
+}
+
+func TestStringFunctionErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		message string
+	}{
+		{
+			name:    "printf quoting conversion",
+			input:   "SELECT printf('INSERT INTO t VALUES(%Q)', v) FROM t",
+			message: "printf conversion %Q has no DuckDB equivalent",
+		},
+		{
+			name:    "printf thousands separator",
+			input:   "SELECT printf('%,d', n) FROM t",
+			message: "printf conversion %,d has no DuckDB equivalent",
+		},
+		{
+			name:    "soundex",
+			input:   "SELECT soundex(name) FROM users",
+			message: "soundex has no DuckDB equivalent",
+		},
+		{
+			name:    "glob with one argument",
+			input:   "SELECT glob('*') FROM t",
+			message: "glob takes a pattern and a string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			core.Visit(createParseTree(t, &core, tt.input))
+
+			errs := core.diagnostics.errors()
+			if len(errs) != 1 || errs[0].Msg != tt.message {
+				t.Errorf("got diagnostics %v, want error %q", core.diagnostics, tt.message)
+			}
+		})
+	}
+}
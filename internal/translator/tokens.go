@@ -0,0 +1,306 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// tokenFilter sits between the lexer and the parser to cover syntax newer
+// than the grammar:
+//
+//   - The MATERIALIZED and NOT MATERIALIZED hints of common table
+//     expressions move to the hidden channel, so they stay in the token
+//     stream for materializedHint to find.
+//   - The JSON operators -> and ->>, which lex as a minus followed by a
+//     greater than, become a single token of the same type as ||. SQLite
+//     gives them the precedence of ||, and the token text tells them apart.
+//   - An ORDER BY inside the parentheses of a function call, which orders
+//     the input of an aggregate, moves to the hidden channel together with
+//     its terms, so callOrder can find it.
+//   - A semicolon ending an empty statement, at the start of the input or
+//     after another semicolon, moves to the hidden channel. The grammar
+//     wants a statement before each one, SQLite ignores them.
+type tokenFilter struct {
+	antlr.Lexer
+	buffered []antlr.Token
+
+	// parens has an entry for every open parenthesis
+	parens []paren
+	// previous is the type of the last token on the default channel
+	previous int
+}
+
+type paren struct {
+	// call is set when the parenthesis opens the arguments of a function
+	call bool
+	// ordered is set once an ORDER BY was found among the arguments
+	ordered bool
+}
+
+func newTokenFilter(lexer antlr.Lexer) *tokenFilter {
+	return &tokenFilter{Lexer: lexer}
+}
+
+func (f *tokenFilter) NextToken() antlr.Token {
+	if len(f.buffered) == 0 {
+		f.buffered = f.lookahead()
+	}
+	tok := f.buffered[0]
+	f.buffered = f.buffered[1:]
+	return f.callOrder(f.emptyStatement(tok))
+}
+
+// emptyStatement hides tok if it is a semicolon with no statement before it.
+func (f *tokenFilter) emptyStatement(tok antlr.Token) antlr.Token {
+	if tok.GetTokenType() != parser.SQLiteLexerSCOL || tok.GetChannel() != antlr.TokenDefaultChannel {
+		return tok
+	}
+	if f.previous == 0 || f.previous == parser.SQLiteLexerSCOL {
+		return hiddenCopy(tok)
+	}
+	return tok
+}
+
+// callOrder hides tok if it is part of an ORDER BY among the arguments of
+// a function call.
+func (f *tokenFilter) callOrder(tok antlr.Token) antlr.Token {
+	if tok.GetChannel() != antlr.TokenDefaultChannel {
+		return tok
+	}
+
+	tokenType := tok.GetTokenType()
+	switch tokenType {
+	case antlr.TokenEOF:
+		// The parser has to see the end of the input, parentheses left
+		// open are its to report
+		f.parens = nil
+		f.previous = tokenType
+		return tok
+	case parser.SQLiteLexerOPEN_PAR:
+		f.parens = append(f.parens, paren{call: f.previous == parser.SQLiteLexerIDENTIFIER})
+	case parser.SQLiteLexerCLOSE_PAR:
+		if len(f.parens) > 0 {
+			f.parens = f.parens[:len(f.parens)-1]
+		}
+	case parser.SQLiteLexerORDER_:
+		// An ORDER BY without arguments before it is left to the parser to
+		// reject
+		if n := len(f.parens); n > 0 && f.parens[n-1].call && !f.ordered() && f.previous != parser.SQLiteLexerOPEN_PAR {
+			f.parens[n-1].ordered = true
+		}
+	}
+	f.previous = tokenType
+
+	if f.ordered() {
+		return hiddenCopy(tok)
+	}
+	return tok
+}
+
+func (f *tokenFilter) ordered() bool {
+	for _, p := range f.parens {
+		if p.ordered {
+			return true
+		}
+	}
+	return false
+}
+
+// lookahead reads the next token and as many more as it takes to recognise
+// one of the constructs the filter rewrites.
+func (f *tokenFilter) lookahead() []antlr.Token {
+	tok := f.Lexer.NextToken()
+	switch tok.GetTokenType() {
+	case parser.SQLiteLexerAS_:
+		return f.materializedHint(tok)
+	case parser.SQLiteLexerMINUS:
+		return f.jsonOperator(tok)
+	}
+	return []antlr.Token{tok}
+}
+
+// jsonOperator merges a minus with a directly following > or >>.
+func (f *tokenFilter) jsonOperator(minus antlr.Token) []antlr.Token {
+	next := f.Lexer.NextToken()
+	if next.GetStart() != minus.GetStop()+1 {
+		return []antlr.Token{minus, next}
+	}
+
+	switch next.GetTokenType() {
+	case parser.SQLiteLexerGT, parser.SQLiteLexerGT2:
+		return []antlr.Token{antlr.CommonTokenFactoryDEFAULT.Create(minus.GetSource(), parser.SQLiteLexerPIPE2, "-"+next.GetText(),
+			antlr.TokenDefaultChannel, minus.GetStart(), next.GetStop(), minus.GetLine(), minus.GetColumn())}
+	}
+	return []antlr.Token{minus, next}
+}
+
+// materializedHint reads, after an AS, as many tokens as it takes to tell
+// whether a hint follows: AS [NOT] MATERIALIZED ( SELECT. The opening query
+// keyword keeps a type named MATERIALIZED in a CAST from being mistaken
+// for a hint.
+func (f *tokenFilter) materializedHint(as antlr.Token) []antlr.Token {
+	tokens := []antlr.Token{as}
+
+	var hint []int
+	matched := 0
+	for {
+		tok := f.Lexer.NextToken()
+		tokens = append(tokens, tok)
+		if tok.GetChannel() != antlr.TokenDefaultChannel {
+			continue
+		}
+
+		switch tokenType := tok.GetTokenType(); {
+		case matched == 0 && len(hint) == 0 && tokenType == parser.SQLiteLexerNOT_:
+			hint = append(hint, len(tokens)-1)
+			continue
+		case matched == 0 && tokenType == parser.SQLiteLexerIDENTIFIER && strings.EqualFold(tok.GetText(), "MATERIALIZED"):
+			hint = append(hint, len(tokens)-1)
+			matched = 1
+			continue
+		case matched == 1 && tokenType == parser.SQLiteLexerOPEN_PAR:
+			matched = 2
+			continue
+		case matched == 2 && (tokenType == parser.SQLiteLexerSELECT_ || tokenType == parser.SQLiteLexerVALUES_ || tokenType == parser.SQLiteLexerWITH_):
+			for _, i := range hint {
+				tokens[i] = hiddenCopy(tokens[i])
+			}
+		}
+		return tokens
+	}
+}
+
+func hiddenCopy(tok antlr.Token) antlr.Token {
+	return antlr.CommonTokenFactoryDEFAULT.Create(tok.GetSource(), tok.GetTokenType(), tok.GetText(),
+		antlr.TokenHiddenChannel, tok.GetStart(), tok.GetStop(), tok.GetLine(), tok.GetColumn())
+}
+
+// materializedHint returns the hint hidden between the AS of a common table
+// expression and its opening parenthesis, followed by a space, or "" if
+// there is none.
+func (c *translatorCore) materializedHint(as antlr.TerminalNode) string {
+	if c.tokens == nil || as == nil {
+		return ""
+	}
+
+	var words []string
+	for _, tok := range c.tokens.GetHiddenTokensToRight(as.GetSymbol().GetTokenIndex(), antlr.TokenHiddenChannel) {
+		switch tok.GetTokenType() {
+		case parser.SQLiteLexerNOT_, parser.SQLiteLexerIDENTIFIER:
+			words = append(words, strings.ToUpper(tok.GetText()))
+		}
+	}
+	if len(words) == 0 {
+		return ""
+	}
+	return strings.Join(words, " ") + " "
+}
+
+// callOrder translates the ORDER BY hidden before the closing parenthesis
+// of a function call, preceded by a space, or returns "" if there is none.
+func (c *translatorCore) callOrder(call *parser.ExprContext) string {
+	if c.tokens == nil || call.CLOSE_PAR() == nil {
+		return ""
+	}
+	closing := call.CLOSE_PAR().GetSymbol()
+	order := callOrderTokens(c.tokens, closing)
+	if order == nil {
+		return ""
+	}
+	orderBy, tokens := parseCallOrder(order, closing, nil)
+
+	// The terms order the rows of the aggregate, not those of a compound
+	// SELECT the call may be part of, and calls among them find their own
+	// ORDER BY in the tokens of the terms
+	defer func(compound []parser.ISelect_coreContext, stream *antlr.CommonTokenStream) {
+		c.compound, c.tokens = compound, stream
+	}(c.compound, c.tokens)
+	c.compound, c.tokens = nil, tokens
+	return " " + c.visitString(orderBy)
+}
+
+// callOrderTokens returns the tokens of the ORDER BY hidden before the
+// closing parenthesis of a function call, or nil if there is none.
+func callOrderTokens(tokens *antlr.CommonTokenStream, closing antlr.Token) []antlr.Token {
+	var order []antlr.Token
+	for _, tok := range tokens.GetHiddenTokensToLeft(closing.GetTokenIndex(), antlr.TokenHiddenChannel) {
+		if order != nil || tok.GetTokenType() == parser.SQLiteLexerORDER_ {
+			order = append(order, tok)
+		}
+	}
+	return order
+}
+
+// parseCallOrder parses the hidden tokens of the ORDER BY of a function
+// call, which keep their positions in the input, and returns the tree and
+// the tokens it was parsed from. Errors go to listener if it is not nil.
+// The ORDER BY has to take all tokens up to the closing parenthesis.
+func parseCallOrder(order []antlr.Token, closing antlr.Token, listener *syntaxErrorListener) (parser.IOrder_by_stmtContext, *antlr.CommonTokenStream) {
+	source := &tokenReplay{Lexer: parser.NewSQLiteLexer(antlr.NewInputStream(""))}
+	for _, tok := range order {
+		channel := antlr.TokenDefaultChannel
+		switch tok.GetTokenType() {
+		case parser.SQLiteLexerSPACES, parser.SQLiteLexerSINGLE_LINE_COMMENT, parser.SQLiteLexerMULTILINE_COMMENT:
+			channel = antlr.TokenHiddenChannel
+		}
+		source.tokens = append(source.tokens, antlr.CommonTokenFactoryDEFAULT.Create(tok.GetSource(), tok.GetTokenType(), tok.GetText(),
+			channel, tok.GetStart(), tok.GetStop(), tok.GetLine(), tok.GetColumn()))
+	}
+	// The closing parenthesis ends the input
+	source.tokens = append(source.tokens, antlr.CommonTokenFactoryDEFAULT.Create(closing.GetSource(), antlr.TokenEOF, closing.GetText(),
+		antlr.TokenDefaultChannel, closing.GetStart(), closing.GetStop(), closing.GetLine(), closing.GetColumn()))
+
+	tokens := antlr.NewCommonTokenStream(newTokenFilter(source), antlr.TokenDefaultChannel)
+	p := parser.NewSQLiteParser(tokens)
+	p.RemoveErrorListeners()
+	if listener != nil {
+		p.AddErrorListener(listener)
+	}
+
+	orderBy := p.Order_by_stmt()
+	if tok := p.GetCurrentToken(); listener != nil && tok.GetTokenType() != antlr.TokenEOF {
+		listener.errors = append(listener.errors, &SyntaxError{
+			Line:           tok.GetLine(),
+			Column:         tok.GetColumn(),
+			OffendingToken: tok.GetText(),
+			ExpectedTokens: []string{"')'"},
+			Msg:            fmt.Sprintf("extraneous input '%s' expecting ')'", tok.GetText()),
+		})
+	}
+	repairExpressions(orderBy)
+	return orderBy, tokens
+}
+
+// tokenReplay is a lexer that returns tokens read before, and the last
+// one for good.
+type tokenReplay struct {
+	antlr.Lexer
+	tokens []antlr.Token
+}
+
+func (r *tokenReplay) NextToken() antlr.Token {
+	tok := r.tokens[0]
+	if len(r.tokens) > 1 {
+		r.tokens = r.tokens[1:]
+	}
+	return tok
+}
+
+// checkCallOrders parses the ORDER BYs hidden in function calls, which the
+// parser of the input does not see, to report their syntax errors.
+func checkCallOrders(tokens *antlr.CommonTokenStream, listener *syntaxErrorListener) {
+	tokens.Fill()
+	for _, tok := range tokens.GetAllTokens() {
+		if tok.GetTokenType() != parser.SQLiteLexerCLOSE_PAR || tok.GetChannel() != antlr.TokenDefaultChannel {
+			continue
+		}
+		if order := callOrderTokens(tokens, tok); order != nil {
+			_, orderTokens := parseCallOrder(order, tok, listener)
+			checkCallOrders(orderTokens, listener)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
 
 	"sql-translator/internal/parser"
 
@@ -11,39 +12,185 @@ import (
 )
 
 type SQLiteTranslator struct {
-	input string
-	core  translatorCore
+	input             string
+	schema            string
+	caseSensitiveLike bool
+	core              translatorCore
 }
 
-func NewSQLiteTranslator(input string) *SQLiteTranslator {
-	return &SQLiteTranslator{
-        input: input,
-        core: translatorCore{
-            BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
-        },
-    }	
+// Option configures a SQLiteTranslator.
+type Option func(*SQLiteTranslator)
+
+// WithMode sets how constructs without a translation are handled. The
+// default is Strict.
+func WithMode(mode Mode) Option {
+	return func(t *SQLiteTranslator) {
+		t.core.mode = mode
+	}
+}
+
+// WithTypeMap sets the mapping from SQLite declared types to DuckDB types.
+// Start from DefaultTypeMap to change only part of it.
+func WithTypeMap(types TypeMap) Option {
+	return func(t *SQLiteTranslator) {
+		t.core.types = &types
+	}
+}
+
+// WithSQLiteNullOrder adds NULLS FIRST or NULLS LAST to every ordering term
+// that does not have one, so NULLs sort the way SQLite sorts them: first in
+// ascending order and last in descending order. DuckDB puts them last in
+// both directions by default.
+func WithSQLiteNullOrder(enabled bool) Option {
+	return func(t *SQLiteTranslator) {
+		t.core.nullOrder = enabled
+	}
+}
+
+// WithCaseSensitiveLike keeps LIKE case sensitive, as PRAGMA
+// case_sensitive_like = ON does in SQLite. By default LIKE translates to
+// ILIKE. A case_sensitive_like pragma in the input overrides this option for
+// the statements that follow it.
+func WithCaseSensitiveLike(enabled bool) Option {
+	return func(t *SQLiteTranslator) {
+		t.caseSensitiveLike = enabled
+	}
+}
+
+// WithConcatFunction translates the || operator to concat(). DuckDB's ||
+// gives NULL when an operand is NULL as SQLite's does, concat() skips NULL
+// operands instead. By default || is kept.
+func WithConcatFunction(enabled bool) Option {
+	return func(t *SQLiteTranslator) {
+		t.core.concatFunction = enabled
+	}
+}
+
+// WithArithmetic sets how / and % translate when the translator cannot tell
+// whether their operands are integers. The default is ArithmeticWarn.
+// Integer overflow is not reproduced: SQLite switches to a real result where
+// DuckDB reports an error.
+func WithArithmetic(arithmetic Arithmetic) Option {
+	return func(t *SQLiteTranslator) {
+		t.core.arithmetic = arithmetic
+	}
+}
+
+// WithSchema supplies the CREATE TABLE statements of the database the input
+// runs against. The declared column types tell integer operands apart and
+// INTEGER PRIMARY KEY columns replace references to the rowid, as they do
+// for tables created in the input itself. Other statements are ignored.
+func WithSchema(ddl string) Option {
+	return func(t *SQLiteTranslator) {
+		t.schema = ddl
+	}
+}
+
+func NewSQLiteTranslator(input string, opts ...Option) *SQLiteTranslator {
+	t := &SQLiteTranslator{
+		input: input,
+		core: translatorCore{
+			BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+		},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// Translate converts the input into DuckDB SQL. If the input does not parse,
+// the returned error is a SyntaxErrors listing every problem found. If it
+// parses but cannot be translated, the error is a Diagnostics holding the
+// error diagnostics.
+func (t *SQLiteTranslator) Translate() (string, error) {
+	// Tables created by the input of an earlier call are not kept
+	t.core.schema, t.core.scopes = nil, nil
+	if err := t.loadSchema(); err != nil {
+		return "", err
+	}
+	t.core.diagnostics = nil
+	t.core.caseSensitiveLike = t.caseSensitiveLike
+
+	tree, p, err := t.getSyntaxTree()
+	if err != nil {
+		return "", err
+	}
+
+	t.core.tokens, _ = p.GetTokenStream().(*antlr.CommonTokenStream)
+	query := t.core.visitString(tree)
+	if errs := t.core.diagnostics.errors(); len(errs) > 0 {
+		return "", errs
+	}
+	return query, nil
+}
+
+// Diagnostics returns everything reported by the last call to Translate,
+// including warnings about constructs that were dropped or copied as is.
+func (t *SQLiteTranslator) Diagnostics() Diagnostics {
+	return t.core.diagnostics
 }
 
-func (t *SQLiteTranslator) Translate() string {
-	tree, _ := t.getSyntaxTree()
-	return t.core.Visit(tree).(string)
+// loadSchema defines the tables created by the schema given to WithSchema.
+func (t *SQLiteTranslator) loadSchema() error {
+	if t.schema == "" {
+		return nil
+	}
+
+	tree, p, err := parse(t.schema)
+	if err != nil {
+		return fmt.Errorf("schema: %w", err)
+	}
+	t.core.tokens, _ = p.GetTokenStream().(*antlr.CommonTokenStream)
+	for _, list := range tree.(*parser.ParseContext).AllSql_stmt_list() {
+		for _, stmt := range list.AllSql_stmt() {
+			if create := stmt.Create_table_stmt(); create != nil {
+				t.core.Visit(create)
+			}
+		}
+	}
+	return nil
+}
+
+func (t *SQLiteTranslator) getSyntaxTree() (antlr.ParseTree, *parser.SQLiteParser, error) {
+	return parse(t.input)
 }
 
-func (t *SQLiteTranslator) getSyntaxTree() (antlr.ParseTree, *parser.SQLiteParser) {
-	input := antlr.NewInputStream(t.input)
+func parse(sql string) (antlr.ParseTree, *parser.SQLiteParser, error) {
+	listener := newSyntaxErrorListener()
+
+	input := antlr.NewInputStream(sql)
 	lexer := parser.NewSQLiteLexer(input)
-	stream := antlr.NewCommonTokenStream(lexer, antlr.TokenDefaultChannel)
+	lexer.RemoveErrorListeners()
+	lexer.AddErrorListener(listener)
+
+	stream := antlr.NewCommonTokenStream(newTokenFilter(lexer), antlr.TokenDefaultChannel)
 	p := parser.NewSQLiteParser(stream)
+	p.RemoveErrorListeners()
+	p.AddErrorListener(listener)
+
 	tree := p.Parse()
-	return tree, p
+	checkCallOrders(stream, listener)
+	if len(listener.errors) > 0 {
+		// Errors in ORDER BYs of function calls are found last
+		sort.SliceStable(listener.errors, func(i, j int) bool {
+			a, b := listener.errors[i], listener.errors[j]
+			return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+		})
+		return tree, p, listener.errors
+	}
+	repairCompounds(tree)
+	repairExpressions(tree)
+	return tree, p, nil
 }
 
 func (t *SQLiteTranslator) ShowSyntaxTree() {
 	t.WriteSyntaxTree(os.Stdout)
 }
 
+// WriteSyntaxTree writes the parse tree to w. The tree is written even when
+// the input has syntax errors since it shows how the parser recovered.
 func (t *SQLiteTranslator) WriteSyntaxTree(w io.Writer) {
-	tree, p := t.getSyntaxTree()
+	tree, p, _ := t.getSyntaxTree()
 	fmt.Fprintln(w, tree.ToStringTree(nil, p))
 }
-
@@ -0,0 +1,329 @@
+package translator
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTranslateScriptComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "comment before each statement",
+			input:    "-- all users\nSELECT * FROM users;\n/* all orders */\nSELECT * FROM orders;",
+			expected: "-- all users\nSELECT * FROM users;\n/* all orders */\nSELECT * FROM orders",
+		},
+		{
+			name:     "trailing comment",
+			input:    "SELECT * FROM users; -- done\n",
+			expected: "SELECT * FROM users\n-- done",
+		},
+		{
+			name:     "only comments",
+			input:    "-- nothing to do\n",
+			expected: "-- nothing to do",
+		},
+		{
+			name:     "only empty statements",
+			input:    ";;",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSQLiteTranslator(tt.input).Translate()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslateSyntaxErrors(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          string
+		line           int
+		column         int
+		offendingToken string
+		expectedToken  string
+		message        string
+	}{
+		{
+			name:           "missing table name",
+			input:          "SELECT * FROM",
+			line:           1,
+			column:         13,
+			offendingToken: "<EOF>",
+			expectedToken:  "IDENTIFIER",
+		},
+		{
+			name:           "misspelled keyword",
+			input:          "SELECT id FROM users\nWHER id = 1",
+			line:           2,
+			column:         5,
+			offendingToken: "id",
+		},
+		{
+			name:           "several upsert clauses",
+			input:          "INSERT INTO t (a) VALUES (1) ON CONFLICT (a) DO NOTHING ON CONFLICT DO NOTHING",
+			line:           1,
+			column:         56,
+			offendingToken: "ON",
+			message:        "more than one ON CONFLICT clause, DuckDB accepts a single ON CONFLICT clause per INSERT",
+		},
+		{
+			name:           "aggregate order without terms",
+			input:          "SELECT group_concat(a ORDER BY) FROM t",
+			line:           1,
+			column:         30,
+			offendingToken: ")",
+		},
+		{
+			name:           "aggregate order without arguments",
+			input:          "SELECT count(ORDER BY a) FROM t",
+			line:           1,
+			column:         12,
+			offendingToken: "(",
+		},
+		{
+			name:           "aggregate order given twice",
+			input:          "SELECT f(a ORDER BY b ORDER BY c) FROM t",
+			line:           1,
+			column:         22,
+			offendingToken: "ORDER",
+			message:        "extraneous input 'ORDER' expecting ')'",
+		},
+		{
+			name:           "unexpected character",
+			input:          "SELECT id FROM users WHERE id = 1 #",
+			line:           1,
+			column:         34,
+			offendingToken: "#",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSQLiteTranslator(tt.input).Translate()
+			if err == nil {
+				t.Fatalf("expected an error, got %q", got)
+			}
+
+			var syntaxErrs SyntaxErrors
+			if !errors.As(err, &syntaxErrs) {
+				t.Fatalf("got %T, want SyntaxErrors", err)
+			}
+
+			first := syntaxErrs[0]
+			if first.Line != tt.line || first.Column != tt.column {
+				t.Errorf("got position %d:%d, want %d:%d", first.Line, first.Column, tt.line, tt.column)
+			}
+			if first.OffendingToken != tt.offendingToken {
+				t.Errorf("got offending token %q, want %q", first.OffendingToken, tt.offendingToken)
+			}
+			if tt.message != "" && first.Msg != tt.message {
+				t.Errorf("got message %q, want %q", first.Msg, tt.message)
+			}
+			if tt.expectedToken != "" && !containsString(first.ExpectedTokens, tt.expectedToken) {
+				t.Errorf("expected tokens %v do not include %q", first.ExpectedTokens, tt.expectedToken)
+			}
+		})
+	}
+}
+
+func TestTranslateUnclosedCalls(t *testing.T) {
+	inputs := []string{
+		"SELECT count(a ORDER BY b",
+		"SELECT group_concat(a ORDER BY b FROM t",
+	}
+
+	for _, input := range inputs {
+		t.Run(input, func(t *testing.T) {
+			done := make(chan error, 1)
+			go func() {
+				_, err := NewSQLiteTranslator(input).Translate()
+				done <- err
+			}()
+
+			select {
+			case err := <-done:
+				var syntaxErrs SyntaxErrors
+				if !errors.As(err, &syntaxErrs) {
+					t.Errorf("got %v, want SyntaxErrors", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("translation did not return")
+			}
+		})
+	}
+}
+
+func containsString(values []string, want string) bool {
+	for _, v := range values {
+		if v == want {
+			return true
+		}
+	}
+	return false
+}
+
+func TestTranslateModes(t *testing.T) {
+	input := "SELECT value FROM generate_series(1, 10)"
+
+	_, err := NewSQLiteTranslator(input).Translate()
+	var diags Diagnostics
+	if !errors.As(err, &diags) {
+		t.Fatalf("strict mode: got %v, want Diagnostics", err)
+	}
+
+	tr := NewSQLiteTranslator(input, WithMode(Lenient))
+	got, err := tr.Translate()
+	if err != nil {
+		t.Fatalf("lenient mode: unexpected error: %v", err)
+	}
+	if got != input {
+		t.Errorf("lenient mode: got %q, want %q", got, input)
+	}
+	if len(tr.Diagnostics()) != 1 || tr.Diagnostics()[0].Severity != SeverityWarning {
+		t.Errorf("lenient mode: got diagnostics %v, want one warning", tr.Diagnostics())
+	}
+}
+
+func TestTranslateMaterializedHints(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "materialized",
+			input:    "WITH t AS materialized (SELECT 1 AS v) SELECT v FROM t",
+			expected: "WITH t AS MATERIALIZED (SELECT 1 AS v) SELECT v FROM t",
+		},
+		{
+			name:     "not materialized",
+			input:    "WITH t AS NOT MATERIALIZED (SELECT 1), u AS (SELECT 2) DELETE FROM x WHERE y IN (SELECT * FROM t)",
+			expected: "WITH t AS NOT MATERIALIZED (SELECT 1), u AS (SELECT 2) DELETE FROM x WHERE y IN (SELECT * FROM t)",
+		},
+		{
+			name:     "materialized values",
+			input:    "WITH c AS MATERIALIZED (VALUES (1)) SELECT * FROM c",
+			expected: "WITH c AS MATERIALIZED (VALUES (1)) SELECT * FROM c",
+		},
+		{
+			name:     "alias named materialized",
+			input:    "SELECT 1 AS materialized",
+			expected: "SELECT 1 AS materialized",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSQLiteTranslator(tt.input).Translate()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslateOrderedAggregates(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "group_concat",
+			input:    "SELECT group_concat(name, ', ' ORDER BY name DESC) FROM staff",
+			expected: "SELECT string_agg(name, ', ' ORDER BY name DESC) FROM staff",
+		},
+		{
+			name:     "distinct with a function in the order",
+			input:    "SELECT group_concat(DISTINCT tag ORDER BY lower(tag)), count(*) FROM tags",
+			expected: "SELECT string_agg(DISTINCT tag, ',' ORDER BY lower(tag)), count(*) FROM tags",
+		},
+		{
+			name:     "other aggregates",
+			input:    "SELECT json_group_array(v ORDER BY k) FILTER (WHERE v > 0) FROM t ORDER BY 1",
+			expected: "SELECT json_group_array(v ORDER BY k) FILTER (WHERE v > 0) FROM t ORDER BY 1",
+		},
+		{
+			name:     "ordered call among the terms",
+			input:    "SELECT group_concat(a ORDER BY json_group_array(b ORDER BY c) DESC) FROM t",
+			expected: "SELECT string_agg(a, ',' ORDER BY json_group_array(b ORDER BY c) DESC) FROM t",
+		},
+		{
+			name:     "subquery order is kept",
+			input:    "SELECT max((SELECT b FROM u ORDER BY b LIMIT 1)) FROM t",
+			expected: "SELECT max((SELECT b FROM u ORDER BY b LIMIT 1)) FROM t",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSQLiteTranslator(tt.input).Translate()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTranslateWithSchema(t *testing.T) {
+	schema := "CREATE TABLE orders (id INTEGER PRIMARY KEY, qty INTEGER, total REAL); CREATE INDEX orders_qty ON orders (qty);"
+
+	got, err := NewSQLiteTranslator("SELECT rowid, total / qty, qty / 2 FROM orders", WithSchema(schema)).Translate()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "SELECT id, total / qty, qty // 2 FROM orders"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := NewSQLiteTranslator("SELECT 1", WithSchema("CREATE TABLE (")).Translate(); err == nil {
+		t.Error("expected an error for a schema that does not parse")
+	}
+}
+
+func TestTranslateTwice(t *testing.T) {
+	tr := NewSQLiteTranslator("SELECT rowid, a / b FROM t",
+		WithSchema("CREATE TABLE t (a INTEGER, b INTEGER, c INTEGER, d INTEGER DEFAULT (abs(1)))"))
+	want := "SELECT rowid, a // b FROM t"
+
+	for i := 0; i < 2; i++ {
+		got, err := tr.Translate()
+		if err != nil {
+			t.Fatalf("call %d: unexpected error: %v", i+1, err)
+		}
+		if got != want {
+			t.Errorf("call %d: got %q, want %q", i+1, got, want)
+		}
+	}
+
+	// Tables created by the input of the first call are gone in the second
+	tr = NewSQLiteTranslator("CREATE TABLE u (k INTEGER PRIMARY KEY); SELECT rowid FROM u")
+	if _, err := tr.Translate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tr.input = "SELECT rowid FROM u"
+	if got, err := tr.Translate(); err != nil || got != "SELECT rowid FROM u" {
+		t.Errorf("got %q, %v, want %q", got, err, "SELECT rowid FROM u")
+	}
+}
@@ -0,0 +1,103 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TypeMap maps SQLite declared column types to DuckDB types. SQLite accepts
+// any type name and only derives an affinity from it, so types not found in
+// Names are mapped through the affinity rules of
+// https://www.sqlite.org/datatype3.html#determination_of_column_affinity.
+type TypeMap struct {
+	// Names maps upper-case declared type names, without size arguments,
+	// to DuckDB types. It is consulted before the affinity rules.
+	Names map[string]string
+
+	Integer string
+	Text    string
+	Real    string
+	Blob    string
+	// Numeric is used for NUMERIC affinity types without size arguments,
+	// with them the type becomes a DECIMAL of that precision and scale.
+	Numeric string
+	// Untyped is used for columns declared without a type.
+	Untyped string
+}
+
+var defaultTypeMap = DefaultTypeMap()
+
+// DefaultTypeMap returns the mapping used unless WithTypeMap is given.
+// INTEGER affinity maps to BIGINT since SQLite integers are 64-bit. NUMERIC
+// affinity maps to DOUBLE, a bare DECIMAL is DECIMAL(18, 3) in DuckDB and
+// drops digits SQLite keeps.
+func DefaultTypeMap() TypeMap {
+	return TypeMap{
+		Names: map[string]string{
+			"BOOLEAN":   "BOOLEAN",
+			"BOOL":      "BOOLEAN",
+			"DATE":      "DATE",
+			"DATETIME":  "TIMESTAMP",
+			"TIMESTAMP": "TIMESTAMP",
+		},
+		Integer: "BIGINT",
+		Text:    "VARCHAR",
+		Real:    "DOUBLE",
+		Blob:    "BLOB",
+		Numeric: "DOUBLE",
+		Untyped: "VARCHAR",
+	}
+}
+
+// duckDBType returns the DuckDB type for a declared SQLite type name and its
+// optional size arguments, e.g. ("DECIMAL", "10", "2").
+func (m TypeMap) duckDBType(name string, args ...string) string {
+	name = strings.ToUpper(strings.Join(strings.Fields(name), " "))
+	if duckType, ok := m.Names[name]; ok {
+		return duckType
+	}
+
+	switch {
+	case name == "":
+		return m.Untyped
+	case strings.Contains(name, "INT"):
+		return m.Integer
+	case strings.Contains(name, "CHAR"), strings.Contains(name, "CLOB"), strings.Contains(name, "TEXT"):
+		return m.Text
+	case strings.Contains(name, "BLOB"):
+		return m.Blob
+	case strings.Contains(name, "REAL"), strings.Contains(name, "FLOA"), strings.Contains(name, "DOUB"):
+		return m.Real
+	}
+
+	// NUMERIC affinity keeps precision and scale, DECIMAL(10, 2) stays as is
+	if len(args) > 0 {
+		return fmt.Sprintf("DECIMAL(%s)", strings.Join(args, ", "))
+	}
+	return m.Numeric
+}
+
+// Affinities of SQLite columns.
+const (
+	integerAffinity = "INTEGER"
+	textAffinity    = "TEXT"
+	blobAffinity    = "BLOB"
+	realAffinity    = "REAL"
+	numericAffinity = "NUMERIC"
+)
+
+// affinity returns the affinity SQLite derives from a declared type name.
+func affinity(name string) string {
+	name = strings.ToUpper(name)
+	switch {
+	case strings.Contains(name, "INT"):
+		return integerAffinity
+	case strings.Contains(name, "CHAR"), strings.Contains(name, "CLOB"), strings.Contains(name, "TEXT"):
+		return textAffinity
+	case name == "", strings.Contains(name, "BLOB"):
+		return blobAffinity
+	case strings.Contains(name, "REAL"), strings.Contains(name, "FLOA"), strings.Contains(name, "DOUB"):
+		return realAffinity
+	}
+	return numericAffinity
+}
@@ -0,0 +1,127 @@
+package translator
+
+import (
+	"fmt"
+	"strings"
+
+	"sql-translator/internal/parser"
+
+	"github.com/antlr4-go/antlr/v4"
+)
+
+// DuckDB implements the SQL standard window syntax SQLite follows, so window
+// functions translate clause by clause without any rewriting.
+
+func (c *translatorCore) VisitFilter_clause(ctx *parser.Filter_clauseContext) any {
+	return fmt.Sprintf("FILTER (WHERE %s)", c.Visit(ctx.Expr()))
+}
+
+func (c *translatorCore) VisitOver_clause(ctx *parser.Over_clauseContext) any {
+	if name := ctx.Window_name(); name != nil {
+		return fmt.Sprintf("OVER %s", c.name(name))
+	}
+	return fmt.Sprintf("OVER (%s)", c.windowSpec(ctx.Base_window_name(), ctx.AllExpr(), ctx.AllOrdering_term(), ctx.Frame_spec()))
+}
+
+func (c *translatorCore) VisitWindow_defn(ctx *parser.Window_defnContext) any {
+	return fmt.Sprintf("(%s)", c.windowSpec(ctx.Base_window_name(), ctx.AllExpr(), ctx.AllOrdering_term(), ctx.Frame_spec()))
+}
+
+// windowSpec renders the inside of the parentheses of a window definition,
+// shared by OVER (...) and WINDOW name AS (...).
+func (c *translatorCore) windowSpec(base parser.IBase_window_nameContext, partition []parser.IExprContext, order []parser.IOrdering_termContext, frame parser.IFrame_specContext) string {
+	var parts []string
+	if base != nil {
+		parts = append(parts, c.name(base))
+	}
+
+	if len(partition) > 0 {
+		var exprs []string
+		for _, expr := range partition {
+			exprs = append(exprs, c.visitString(expr))
+		}
+		parts = append(parts, fmt.Sprintf("PARTITION BY %s", strings.Join(exprs, ", ")))
+	}
+
+	if len(order) > 0 {
+		var terms []string
+		for _, term := range order {
+			terms = append(terms, c.visitString(term))
+		}
+		parts = append(parts, fmt.Sprintf("ORDER BY %s", strings.Join(terms, ", ")))
+	}
+
+	if frame != nil {
+		parts = append(parts, c.visitString(frame))
+	}
+	return strings.Join(parts, " ")
+}
+
+// buildWindowClause renders the named windows of a select core.
+func (c *translatorCore) buildWindowClause(ctx *parser.Select_coreContext) string {
+	if ctx.WINDOW_() == nil {
+		return ""
+	}
+
+	var windows []string
+	for i, name := range ctx.AllWindow_name() {
+		windows = append(windows, fmt.Sprintf("%s AS %s", c.name(name), c.Visit(ctx.Window_defn(i))))
+	}
+	return fmt.Sprintf("WINDOW %s", strings.Join(windows, ", "))
+}
+
+func (c *translatorCore) VisitFrame_spec(ctx *parser.Frame_specContext) any {
+	frame := c.visitString(ctx.Frame_clause())
+
+	if ctx.EXCLUDE_() != nil {
+		switch {
+		case ctx.NO_() != nil:
+			frame = fmt.Sprintf("%s EXCLUDE NO OTHERS", frame)
+		case ctx.CURRENT_() != nil:
+			frame = fmt.Sprintf("%s EXCLUDE CURRENT ROW", frame)
+		case ctx.GROUP_() != nil:
+			frame = fmt.Sprintf("%s EXCLUDE GROUP", frame)
+		case ctx.TIES_() != nil:
+			frame = fmt.Sprintf("%s EXCLUDE TIES", frame)
+		}
+	}
+	return frame
+}
+
+func (c *translatorCore) VisitFrame_clause(ctx *parser.Frame_clauseContext) any {
+	var mode string
+	switch {
+	case ctx.ROWS_() != nil:
+		mode = "ROWS"
+	case ctx.RANGE_() != nil:
+		mode = "RANGE"
+	default:
+		mode = "GROUPS"
+	}
+
+	if single := ctx.Frame_single(); single != nil {
+		return fmt.Sprintf("%s %s", mode, c.frameBound(single))
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", mode, c.frameBound(ctx.Frame_left()), c.frameBound(ctx.Frame_right()))
+}
+
+// frameBound renders one end of a frame: an offset expression or UNBOUNDED
+// followed by PRECEDING or FOLLOWING, or CURRENT ROW.
+func (c *translatorCore) frameBound(ctx antlr.ParserRuleContext) string {
+	var words []string
+	for _, child := range ctx.GetChildren() {
+		switch child := child.(type) {
+		case *parser.ExprContext:
+			// The grammar lets UNBOUNDED PRECEDING parse as a column named
+			// unbounded, SQLite never reads it that way
+			if child.GetStart().GetTokenType() == parser.SQLiteParserUNBOUNDED_ && child.GetStart() == child.GetStop() {
+				words = append(words, "UNBOUNDED")
+				continue
+			}
+			words = append(words, c.visitString(child))
+		case antlr.TerminalNode:
+			words = append(words, strings.ToUpper(child.GetText()))
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,69 @@
+package translator
+
+import (
+	"testing"
+
+	"sql-translator/internal/parser"
+)
+
+func TestWindowFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "ranking",
+			input:    "SELECT row_number() OVER (ORDER BY score DESC), rank() over (partition by team order by score desc) FROM players",
+			expected: "SELECT row_number() OVER (ORDER BY score DESC), rank() OVER (PARTITION BY team ORDER BY score DESC) FROM players",
+		},
+		{
+			name:     "offset functions",
+			input:    "SELECT lag(v, 1, 0) OVER (ORDER BY ts), lead(v) OVER (ORDER BY ts), ntile(4) OVER (ORDER BY v), nth_value(v, 2) OVER (PARTITION BY a, b ORDER BY ts) FROM t",
+			expected: "SELECT lag(v, 1, 0) OVER (ORDER BY ts), lead(v) OVER (ORDER BY ts), ntile(4) OVER (ORDER BY v), nth_value(v, 2) OVER (PARTITION BY a, b ORDER BY ts) FROM t",
+		},
+		{
+			name:     "aggregate over a frame",
+			input:    "SELECT sum(amount) OVER (ORDER BY day ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) FROM sales",
+			expected: "SELECT sum(amount) OVER (ORDER BY day ROWS BETWEEN 6 PRECEDING AND CURRENT ROW) FROM sales",
+		},
+		{
+			name:     "frame shorthand and exclusion",
+			input:    "SELECT first_value(v) OVER (ORDER BY ts range unbounded preceding exclude current row), avg(v) OVER (ORDER BY ts GROUPS BETWEEN 1 PRECEDING AND UNBOUNDED FOLLOWING EXCLUDE TIES) FROM t",
+			expected: "SELECT first_value(v) OVER (ORDER BY ts RANGE UNBOUNDED PRECEDING EXCLUDE CURRENT ROW), avg(v) OVER (ORDER BY ts GROUPS BETWEEN 1 PRECEDING AND UNBOUNDED FOLLOWING EXCLUDE TIES) FROM t",
+		},
+		{
+			name:     "named windows",
+			input:    "SELECT count(*) OVER w, max(v) OVER (w ROWS 2 PRECEDING) FROM t WINDOW w AS (PARTITION BY k ORDER BY ts), w2 AS (w ORDER BY v)",
+			expected: "SELECT count(*) OVER w, max(v) OVER (w ROWS 2 PRECEDING) FROM t WINDOW w AS (PARTITION BY k ORDER BY ts), w2 AS (w ORDER BY v)",
+		},
+		{
+			name:     "filter",
+			input:    "SELECT count(*) FILTER (WHERE ok), sum(v) FILTER (WHERE v > 0) OVER (PARTITION BY k) FROM t",
+			expected: "SELECT count(*) FILTER (WHERE ok), sum(v) FILTER (WHERE v > 0) OVER (PARTITION BY k) FROM t",
+		},
+		{
+			name:     "window in order by",
+			input:    "SELECT name FROM t ORDER BY dense_rank() OVER (ORDER BY v)",
+			expected: "SELECT name FROM t ORDER BY dense_rank() OVER (ORDER BY v)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := translatorCore{
+				BaseSQLiteParserVisitor: &parser.BaseSQLiteParserVisitor{},
+			}
+
+			tree := createParseTree(t, &core, tt.input)
+			got := core.Visit(tree).(string)
+
+			if got != tt.expected {
+				t.Errorf("got %q, want %q", got, tt.expected)
+			}
+			if len(core.diagnostics) > 0 {
+				t.Errorf("unexpected diagnostics %v", core.diagnostics)
+			}
+		})
+	}
+}